@@ -1,215 +1,192 @@
 package main
 
 import (
+	"bytes"
 	"errors"
+	"flag"
 	"go/ast"
-	"go/printer"
+	"go/format"
 	"go/token"
-	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
-	"strings"
-	"unicode"
 
+	"github.com/ngicks/go-example-code-generation/ast/rewrite/directive"
+	"github.com/ngicks/go-example-code-generation/ast/rewrite/driver"
+	"github.com/ngicks/go-example-code-generation/ast/rewrite/reflow"
+	"github.com/ngicks/go-example-code-generation/enum"
 	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/packages"
 )
 
 func main() {
-	cfg := &packages.Config{
-		Mode: packages.NeedName |
-			packages.NeedImports |
-			packages.NeedTypes |
-			packages.NeedSyntax,
-	}
-	pkgs, err := packages.Load(cfg, "./ast/rewrite/target")
+	cfg, err := driver.ParseFlags(
+		"astutil",
+		os.Args[1:],
+		filepath.Join("ast", "rewrite", "astutil", "generated"),
+		"./ast/rewrite/target",
+	)
 	if err != nil {
-		panic(err)
-	}
-
-	generatedDir := filepath.Join("ast", "rewrite", "astutil", "generated")
-	err = os.Mkdir(generatedDir, fs.ModePerm)
-	if err != nil && !errors.Is(err, fs.ErrExist) {
-		panic(err)
-	}
-
-	pkg := pkgs[0]
-
-	for _, f := range pkg.Syntax {
-		cm := ast.NewCommentMap(pkg.Fset, f, f.Comments)
-		filename := filepath.Base(pkg.Fset.Position(f.FileStart).Filename)
-		astutil.Apply(
-			f,
-			func(c *astutil.Cursor) bool {
-				n := c.Node()
-				switch x := n.(type) {
-				default:
-					return true
-				case *ast.FuncDecl:
-				case *ast.GenDecl:
-					if x.Tok != token.TYPE {
-						break
-					}
-
-					if len(x.Specs) == 1 {
-						name, ok := isStringBasedType(x.Specs[0])
-						if !ok {
-							break
-						}
-						param, ok := parseDirective(x.Doc)
-						if !ok {
-							break
-						}
-						param.Name = name
-						addOrReplaceEnum(c, param, cm)
-					}
-				}
-				return false
-			},
-			nil,
-		)
-
-		f.Comments = cm.Comments()
-
-		out, err := os.Create(filepath.Join(generatedDir, filename))
-		if err != nil {
-			panic(err)
+		if errors.Is(err, flag.ErrHelp) {
+			return
 		}
-
-		err = printer.Fprint(out, pkg.Fset, f)
-		if err != nil {
-			panic(err)
-		}
-	}
-}
-
-func isStringBasedType(spec ast.Spec) (string, bool) {
-	typ, ok := spec.(*ast.TypeSpec)
-	if !ok {
-		return "", false
-	}
-	id, ok := typ.Type.(*ast.Ident)
-	if !ok {
-		return "", false
-	}
-	return typ.Name.Name, id.Name == "string"
-}
-
-type EnumParam struct {
-	Name     string
-	Variants []string
-}
-
-func parseDirective(cg *ast.CommentGroup) (EnumParam, bool) {
-	for _, comment := range cg.List {
-		c := strings.TrimLeftFunc(stripMarker(comment.Text), unicode.IsSpace)
-		c, isDirection := strings.CutPrefix(c, "enum:variants=")
-		if !isDirection {
-			continue
-		}
-		return EnumParam{Variants: strings.Split(c, ",")}, true
+		os.Exit(2)
 	}
-	return EnumParam{}, false
-}
 
-func stripMarker(text string) string {
-	if len(text) < 2 {
-		return text
-	}
-	switch text[1] {
-	case '/':
-		return text[2:]
-	case '*':
-		return text[2 : len(text)-2]
-	}
-	return text
+	os.Exit(driver.Run(cfg, rewrite))
 }
 
-func addOrReplaceEnum(c *astutil.Cursor, param EnumParam, cm ast.CommentMap) {
-	found := false
+// rewrite generates enums only, since cfg.Generators is left to the default of the driver,
+// so values of generations are enum.EnumParam.
+func rewrite(pkg *packages.Package, f *ast.File, plan driver.FilePlan, diags *driver.Diagnostics) ([]byte, error) {
+	ed := reflow.NewEditor(pkg.Fset, f)
 	astutil.Apply(
-		c.Parent(),
+		f,
 		func(c *astutil.Cursor) bool {
-			node := c.Node()
-			switch x := node.(type) {
+			n := c.Node()
+			switch n.(type) {
 			default:
 				return true
-			case *ast.FuncDecl:
-			case *ast.GenDecl:
-				if x.Tok != token.CONST {
+			case *ast.FuncDecl, *ast.GenDecl:
+				decl := n.(ast.Decl)
+				if gen, ok := plan.Replace[decl]; ok {
+					decls := astDecls(gen.Value.(enum.EnumParam), declDoc(decl))
+					ed.Replace(decl, decls[0])
+					for _, d := range decls[1:] {
+						ed.InsertAfter(decl, d)
+					}
 					break
 				}
-				if !isGeneratedFor(x.Doc, param.Name) {
+				if slices.Contains(plan.Remove, decl) {
+					ed.Delete(decl)
 					break
 				}
-				found = true
-				newDecl := astVariants(param, x.TokPos)
-				delete(cm, x)
-				cm[newDecl] = append(cm[newDecl], newDecl.Doc)
-				c.Replace(newDecl)
+				typeDecl, ok := decl.(*ast.GenDecl)
+				if !ok || typeDecl.Tok != token.TYPE {
+					break
+				}
+				for _, spec := range typeDecl.Specs {
+					for _, gen := range plan.InsertAfter[spec.(*ast.TypeSpec)] {
+						for _, d := range astDecls(gen.Value.(enum.EnumParam), nil) {
+							ed.InsertAfter(typeDecl, d)
+						}
+					}
+				}
 			}
 			return false
 		},
 		nil,
 	)
-	if !found {
-		newDecl := astVariants(param, c.Node().(*ast.GenDecl).Specs[0].Pos()+30)
-		cm[newDecl] = append(cm[newDecl], newDecl.Doc)
-		c.InsertAfter(newDecl)
+	for _, gen := range plan.Append {
+		for _, d := range astDecls(gen.Value.(enum.EnumParam), nil) {
+			ed.Append(d)
+		}
+	}
+	err := ed.Apply()
+	if err != nil {
+		return nil, err
 	}
+
+	var buf bytes.Buffer
+	err = format.Node(&buf, pkg.Fset, f)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
-func isGeneratedFor(cg *ast.CommentGroup, fotTy string) bool {
-	for _, comment := range cg.List {
-		c := strings.TrimLeftFunc(stripMarker(comment.Text), unicode.IsSpace)
-		s, ok := strings.CutPrefix(c, "enum:generated_for=")
-		if !ok {
-			return false
-		}
-		if s == fotTy {
-			return true
+// astDecls builds declarations generated for param, its const block followed by predicates of its excepts.
+// oldDoc is the doc comment of the declaration they replace, if any.
+func astDecls(param enum.EnumParam, oldDoc *ast.CommentGroup) []ast.Decl {
+	decls := []ast.Decl{astVariants(param, oldDoc)}
+	for _, pred := range astExceptPredicates(param) {
+		pred.Doc = &ast.CommentGroup{List: []*ast.Comment{{Text: directive.GeneratedForMarker(param.Name)}}}
+		decls = append(decls, pred)
+	}
+	return decls
+}
+
+// astExceptPredicates builds predicates of excepts of param, e.g. IsEnumExceptFoo,
+// comparing v to excluded variants so that the file needs no import.
+func astExceptPredicates(param enum.EnumParam) []*ast.FuncDecl {
+	var preds []*ast.FuncDecl
+	for _, except := range param.Excepts {
+		// func IsEnumExceptFoo(v Enum) bool { return v != EnumFoo && v != EnumBar }
+		var result ast.Expr = &ast.Ident{Name: "true"}
+		for i, variant := range except.ExcludedValiants {
+			ne := &ast.BinaryExpr{X: &ast.Ident{Name: "v"}, Op: token.NEQ, Y: &ast.Ident{Name: param.VariantIdent(variant)}}
+			if i == 0 {
+				result = ne
+				continue
+			}
+			result = &ast.BinaryExpr{X: result, Op: token.LAND, Y: ne}
 		}
+		preds = append(preds, &ast.FuncDecl{
+			Name: &ast.Ident{Name: param.ExceptIdent(except.ExceptName)},
+			Type: &ast.FuncType{
+				Params:  &ast.FieldList{List: []*ast.Field{{Names: []*ast.Ident{{Name: "v"}}, Type: &ast.Ident{Name: param.Name}}}},
+				Results: &ast.FieldList{List: []*ast.Field{{Type: &ast.Ident{Name: "bool"}}}},
+			},
+			Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{result}}}},
+		})
+	}
+	return preds
+}
+
+func declDoc(decl ast.Decl) *ast.CommentGroup {
+	switch x := decl.(type) {
+	case *ast.GenDecl:
+		return x.Doc
+	case *ast.FuncDecl:
+		return x.Doc
 	}
-	return false
+	return nil
 }
 
-func astVariants(param EnumParam, pos token.Pos) *ast.GenDecl {
+// astVariants builds the const block of param without positions, laid out later by reflow.
+// Doc comments of the block it replaces are kept if they end with the marker.
+func astVariants(param enum.EnumParam, oldDoc *ast.CommentGroup) *ast.GenDecl {
+	marker := directive.GeneratedForMarker(param.Name)
+	doc := &ast.CommentGroup{}
+	if oldDoc != nil && oldDoc.List[len(oldDoc.List)-1].Text == marker {
+		for _, c := range oldDoc.List {
+			doc.List = append(doc.List, &ast.Comment{Text: c.Text})
+		}
+	} else {
+		doc.List = []*ast.Comment{{Text: marker}}
+	}
 	return &ast.GenDecl{
-		Doc: &ast.CommentGroup{
-			List: []*ast.Comment{
-				{
-					Slash: pos,
-					Text:  "//enum:generated_for=" + param.Name,
-				},
-			},
-		},
-		TokPos: token.Pos(int(pos) + len("//enum:generated_for="+param.Name) + 1),
-		Tok:    token.CONST,
-		Lparen: 1,
-		Specs:  mapParamToSpec(param),
-		Rparen: 2,
+		Doc:   doc,
+		Tok:   token.CONST,
+		Specs: mapParamToSpec(param),
 	}
 }
 
-func mapParamToSpec(param EnumParam) []ast.Spec {
+func mapParamToSpec(param enum.EnumParam) []ast.Spec {
 	specs := make([]ast.Spec, len(param.Variants))
 	for i, variant := range param.Variants {
-		specs[i] = &ast.ValueSpec{
-			Names:  []*ast.Ident{{Name: param.Name + capitalize(variant)}},
-			Type:   &ast.Ident{Name: param.Name},
-			Values: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(variant)}},
+		var doc *ast.CommentGroup
+		if lines := param.VariantDoc(variant); len(lines) > 0 {
+			doc = &ast.CommentGroup{}
+			for _, line := range lines {
+				doc.List = append(doc.List, &ast.Comment{Text: line})
+			}
+		}
+		spec := &ast.ValueSpec{
+			Doc:   doc,
+			Names: []*ast.Ident{{Name: param.VariantIdent(variant)}},
+		}
+		switch {
+		case !param.IsInteger():
+			spec.Type = &ast.Ident{Name: param.Name}
+			spec.Values = []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(variant)}}
+		case i == 0:
+			// Following variants repeat the type and iota of the first one.
+			spec.Type = &ast.Ident{Name: param.Name}
+			spec.Values = []ast.Expr{&ast.Ident{Name: "iota"}}
 		}
+		specs[i] = spec
 	}
 	return specs
 }
-
-func capitalize(s string) string {
-	if len(s) == 0 {
-		return s
-	}
-	if len(s) == 1 {
-		return strings.ToUpper(s)
-	}
-	return strings.ToUpper(s[:1]) + s[1:]
-}
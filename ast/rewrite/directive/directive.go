@@ -0,0 +1,315 @@
+// Package directive parses //enum: directives written above type declarations into enum.EnumParam.
+//
+// The grammar is
+//
+//	//enum:variants=foo,bar,"b,az"      variants as a comma separated list
+//	//enum:variant foo "doc text"       a single variant with optional documentation
+//	//enum:except=NotFoo foo,bar        an except named NotFoo excluding foo and bar
+//	//enum:naming=pascal                the naming strategy of identifiers
+//	//enum:ident b-ar BDashAr           the identifier of a variant following the type name, e.g. EnumBDashAr
+//
+// A value is either bare, a run of characters other than white space, ',' and '"',
+// or a Go string literal, which may contain any character with Go escapes, e.g. "b\"ar".
+// Directives may span multiple lines and are accumulated in order.
+//
+// The rewriters mark const blocks they generate with
+//
+//	//enum:generated_for=Enum
+package directive
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ngicks/go-example-code-generation/enum"
+)
+
+// Prefix is the prefix of every directive.
+const Prefix = "enum:"
+
+// GeneratedForKey is the key of the marker of generated const blocks.
+const GeneratedForKey = "generated_for"
+
+// GeneratedForMarker returns the marker comment of the const block generated for typeName.
+func GeneratedForMarker(typeName string) string {
+	return "//" + Prefix + GeneratedForKey + "=" + typeName
+}
+
+// Error is a malformed directive.
+type Error struct {
+	// Comment is the index of the malformed comment in the slice given to Parse.
+	Comment int
+	// Offset is the byte offset of the error in the comment text, including the comment marker.
+	Offset int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+// Parse parses directives in comments, raw texts of comments including markers such as "//".
+// Comments other than directives are ignored.
+// It reports false if comments contain no directive, other than the generated_for marker.
+// Name of the returned param is left empty.
+func Parse(comments []string) (enum.EnumParam, bool, error) {
+	var (
+		param enum.EnumParam
+		found bool
+	)
+	for i, text := range comments {
+		body, offset := directiveBody(text)
+		if offset < 0 {
+			continue
+		}
+		key, rest, sep := cutKey(body)
+		s := &scanner{src: rest, base: offset + len(Prefix) + len(key) + len(sep), comment: i}
+
+		var err error
+		switch {
+		case key == "variants" && sep == "=":
+			var variants []string
+			variants, err = s.list()
+			param.Variants = append(param.Variants, variants...)
+		case key == "variant" && sep == " ":
+			err = parseVariant(s, &param)
+		case key == "except" && sep == "=":
+			err = parseExcept(s, &param)
+		case key == "naming" && sep == "=":
+			param.Naming, err = s.value()
+		case key == "ident" && sep == " ":
+			err = parseIdent(s, &param)
+		case key == GeneratedForKey && sep == "=":
+			continue
+		case key == "variants" || key == "except" || key == "naming":
+			return enum.EnumParam{}, false, s.errorf(-len(sep)-len(key), "%s%s must be followed by '='", Prefix, key)
+		case key == "variant":
+			return enum.EnumParam{}, false, s.errorf(-len(sep)-len(key), "%svariant must be followed by a space, e.g. //enum:variant foo", Prefix)
+		case key == "ident":
+			return enum.EnumParam{}, false, s.errorf(-len(sep)-len(key), "%sident must be followed by a space, e.g. //enum:ident b-ar BDashAr", Prefix)
+		default:
+			return enum.EnumParam{}, false, s.errorf(-len(sep)-len(key), "unknown directive %q", Prefix+key)
+		}
+		if err != nil {
+			return enum.EnumParam{}, false, err
+		}
+		if err := s.end(); err != nil {
+			return enum.EnumParam{}, false, err
+		}
+		found = true
+	}
+	return param, found, nil
+}
+
+// GeneratedFor reports whether comments contain the generated_for marker for typeName.
+func GeneratedFor(comments []string, typeName string) bool {
+	name, ok := GeneratedForType(comments)
+	return ok && name == typeName
+}
+
+// GeneratedForType returns the type name of the generated_for marker in comments.
+func GeneratedForType(comments []string) (string, bool) {
+	for _, text := range comments {
+		body, offset := directiveBody(text)
+		if offset < 0 {
+			continue
+		}
+		key, rest, sep := cutKey(body)
+		if key == GeneratedForKey && sep == "=" {
+			return strings.TrimSpace(rest), true
+		}
+	}
+	return "", false
+}
+
+func parseVariant(s *scanner, param *enum.EnumParam) error {
+	variant, err := s.value()
+	if err != nil {
+		return err
+	}
+	param.Variants = append(param.Variants, variant)
+	if s.skipSpace(); s.eof() {
+		return nil
+	}
+	if c := s.peek(); c != '"' && c != '`' {
+		return s.errorf(0, "documentation of variant %q must be a quoted string", variant)
+	}
+	doc, err := s.value()
+	if err != nil {
+		return err
+	}
+	if param.Docs == nil {
+		param.Docs = map[string]string{}
+	}
+	param.Docs[variant] = doc
+	return nil
+}
+
+func parseIdent(s *scanner, param *enum.EnumParam) error {
+	variant, err := s.value()
+	if err != nil {
+		return err
+	}
+	if s.skipSpace(); s.eof() {
+		return s.errorf(0, "ident of variant %q must be followed by its identifier", variant)
+	}
+	ident, err := s.value()
+	if err != nil {
+		return err
+	}
+	if param.Idents == nil {
+		param.Idents = map[string]string{}
+	}
+	param.Idents[variant] = ident
+	return nil
+}
+
+func parseExcept(s *scanner, param *enum.EnumParam) error {
+	name, err := s.value()
+	if err != nil {
+		return err
+	}
+	if s.skipSpace(); s.eof() {
+		return s.errorf(0, "except %q must be followed by excluded variants", name)
+	}
+	excluded, err := s.list()
+	if err != nil {
+		return err
+	}
+	param.Excepts = append(param.Excepts, enum.EnumExceptParam{ExceptName: name, ExcludedValiants: excluded})
+	return nil
+}
+
+// directiveBody returns text after the comment marker and the directive prefix, and its offset in text.
+// offset is -1 if text is not a directive.
+func directiveBody(text string) (string, int) {
+	body := StripMarker(text)
+	offset := len(text) - len(body)
+	if strings.HasPrefix(text, "/*") {
+		offset = 2
+	}
+	trimmed := strings.TrimLeftFunc(body, unicode.IsSpace)
+	offset += len(body) - len(trimmed)
+	rest, ok := strings.CutPrefix(trimmed, Prefix)
+	if !ok {
+		return "", -1
+	}
+	return rest, offset
+}
+
+// cutKey splits body into a key and the rest at the first '=' or space.
+func cutKey(body string) (key, rest, sep string) {
+	i := strings.IndexAny(body, "= \t")
+	if i < 0 {
+		return body, "", ""
+	}
+	return body[:i], body[i+1:], body[i : i+1]
+}
+
+// StripMarker removes comment markers, "//" or "/*" and "*/", from text.
+func StripMarker(text string) string {
+	if len(text) < 2 {
+		return text
+	}
+	switch text[1] {
+	case '/':
+		return text[2:]
+	case '*':
+		return text[2 : len(text)-2]
+	}
+	return text
+}
+
+type scanner struct {
+	src     string
+	pos     int
+	base    int
+	comment int
+}
+
+func (s *scanner) eof() bool {
+	return s.pos >= len(s.src)
+}
+
+func (s *scanner) peek() byte {
+	return s.src[s.pos]
+}
+
+func (s *scanner) skipSpace() {
+	for !s.eof() {
+		r, size := utf8.DecodeRuneInString(s.src[s.pos:])
+		if !unicode.IsSpace(r) {
+			return
+		}
+		s.pos += size
+	}
+}
+
+// errorf returns an Error at the current position moved by delta bytes.
+func (s *scanner) errorf(delta int, format string, args ...any) error {
+	return &Error{Comment: s.comment, Offset: s.base + s.pos + delta, Msg: fmt.Sprintf(format, args...)}
+}
+
+// value scans a bare or quoted value.
+func (s *scanner) value() (string, error) {
+	s.skipSpace()
+	if s.eof() {
+		return "", s.errorf(0, "missing value")
+	}
+	if c := s.peek(); c == '"' || c == '`' {
+		quoted, err := strconv.QuotedPrefix(s.src[s.pos:])
+		if err != nil {
+			return "", s.errorf(0, "malformed quoted value %s", s.src[s.pos:])
+		}
+		v, err := strconv.Unquote(quoted)
+		if err != nil {
+			return "", s.errorf(0, "malformed quoted value %s: %v", quoted, err)
+		}
+		s.pos += len(quoted)
+		return v, nil
+	}
+	start := s.pos
+	for !s.eof() {
+		r, size := utf8.DecodeRuneInString(s.src[s.pos:])
+		if unicode.IsSpace(r) || r == ',' {
+			break
+		}
+		if r == '"' || r == '`' {
+			return "", s.errorf(0, "unexpected quote in bare value; quote the whole value instead")
+		}
+		s.pos += size
+	}
+	if s.pos == start {
+		return "", s.errorf(0, "missing value")
+	}
+	return s.src[start:s.pos], nil
+}
+
+// list scans values separated by ',', allowing white space around separators.
+func (s *scanner) list() ([]string, error) {
+	var values []string
+	for {
+		v, err := s.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		s.skipSpace()
+		if s.eof() || s.peek() != ',' {
+			return values, nil
+		}
+		s.pos++
+	}
+}
+
+// end reports an error if anything other than white space is left.
+func (s *scanner) end() error {
+	s.skipSpace()
+	if !s.eof() {
+		return s.errorf(0, "unexpected %q after directive", s.src[s.pos:])
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package directive
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ngicks/go-example-code-generation/enum"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		comments []string
+		param    enum.EnumParam
+		found    bool
+	}{
+		{
+			name:     "no directive",
+			comments: []string{"// Enum is an enum.", "//go:generate foo"},
+		},
+		{
+			name:     "generated_for only",
+			comments: []string{"//enum:generated_for=Enum"},
+		},
+		{
+			name:     "variants",
+			comments: []string{"// Enum is an enum.", "//enum:variants=foo, bar ,baz"},
+			param:    enum.EnumParam{Variants: []string{"foo", "bar", "baz"}},
+			found:    true,
+		},
+		{
+			name:     "quoted variants",
+			comments: []string{`//enum:variants="b,ar","b\"az",` + "`qu ux`"},
+			param:    enum.EnumParam{Variants: []string{"b,ar", `b"az`, "qu ux"}},
+			found:    true,
+		},
+		{
+			name: "variant lines",
+			comments: []string{
+				"//enum:variant foo",
+				`//enum:variant "b ar" "Bar is\na bar."`,
+				"/* enum:variant baz `Baz.` */",
+			},
+			param: enum.EnumParam{
+				Variants: []string{"foo", "b ar", "baz"},
+				Docs:     map[string]string{"b ar": "Bar is\na bar.", "baz": "Baz."},
+			},
+			found: true,
+		},
+		{
+			name: "except and naming",
+			comments: []string{
+				"//enum:variants=foo,bar,baz",
+				"//enum:except=NotFoo foo",
+				`//enum:except="not bar or baz" bar, "baz"`,
+				"//enum:naming=pascal",
+			},
+			param: enum.EnumParam{
+				Variants: []string{"foo", "bar", "baz"},
+				Excepts: []enum.EnumExceptParam{
+					{ExceptName: "NotFoo", ExcludedValiants: []string{"foo"}},
+					{ExceptName: "not bar or baz", ExcludedValiants: []string{"bar", "baz"}},
+				},
+				Naming: "pascal",
+			},
+			found: true,
+		},
+		{
+			name: "ident",
+			comments: []string{
+				"//enum:variants=b-ar,b_ar",
+				`//enum:ident b-ar BDashAr`,
+				`//enum:ident "b_ar" B_ar`,
+			},
+			param: enum.EnumParam{
+				Variants: []string{"b-ar", "b_ar"},
+				Idents:   map[string]string{"b-ar": "BDashAr", "b_ar": "B_ar"},
+			},
+			found: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			param, found, err := Parse(tc.comments)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if found != tc.found {
+				t.Errorf("found: expected %t but got %t", tc.found, found)
+			}
+			if !reflect.DeepEqual(param, tc.param) {
+				t.Errorf("not equal:\nexpected: %#v\nactual:   %#v", tc.param, param)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	for _, tc := range []struct {
+		comments []string
+		comment  int
+		offset   int
+	}{
+		{[]string{"// doc", "//enum:variant=foo"}, 1, 7},
+		{[]string{"//enum:variants foo"}, 0, 7},
+		{[]string{"//enum:varients=foo"}, 0, 7},
+		{[]string{"// enum:variants=foo,"}, 0, 21},
+		{[]string{`//enum:variants=foo,"bar`}, 0, 20},
+		{[]string{`//enum:variants=fo"o`}, 0, 18},
+		{[]string{"//enum:variant foo bar"}, 0, 19},
+		{[]string{"//enum:except=NotFoo"}, 0, 20},
+		{[]string{"//enum:naming=pascal snake"}, 0, 21},
+		{[]string{"//enum:ident=b-ar BDashAr"}, 0, 7},
+		{[]string{"//enum:ident b-ar"}, 0, 17},
+	} {
+		_, _, err := Parse(tc.comments)
+		var dErr *Error
+		if !errors.As(err, &dErr) {
+			t.Errorf("%q: expected *Error but got %v", tc.comments, err)
+			continue
+		}
+		if dErr.Comment != tc.comment || dErr.Offset != tc.offset {
+			t.Errorf(
+				"%q: expected position %d:%d but got %d:%d (%v)",
+				tc.comments, tc.comment, tc.offset, dErr.Comment, dErr.Offset, dErr,
+			)
+		}
+	}
+}
+
+func TestGeneratedFor(t *testing.T) {
+	comments := []string{"// free floating", GeneratedForMarker("Enum")}
+	if !GeneratedFor(comments, "Enum") {
+		t.Errorf("expected true for Enum")
+	}
+	if GeneratedFor(comments, "Enum2") {
+		t.Errorf("expected false for Enum2")
+	}
+}
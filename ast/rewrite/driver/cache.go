@@ -0,0 +1,250 @@
+package driver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/types"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ngicks/go-example-code-generation/internal/atomicfile"
+	"golang.org/x/tools/go/packages"
+)
+
+// Cache remembers rewritten packages so that packages unchanged since the last run are skipped
+// without decorating and printing their files.
+//
+// An entry is keyed by the hash of the rewriter executable, options affecting output, and names and contents of files of a package
+// along with underlying types of its annotated types, which may be declared in dependencies,
+// and holds hashes of output files, actions taken and warnings reported, which are reported again on a hit.
+// It is hit only if outputs on disk still have recorded hashes, so deleted or hand-edited outputs are regenerated.
+// Packages declaring no enum are recorded without outputs.
+//
+// A nil *Cache is valid and never hits. Cache is safe for concurrent use.
+type Cache struct {
+	dir   string
+	salt  []byte
+	force bool
+	cfg   Config
+
+	mu           sync.Mutex
+	hits, misses int
+}
+
+// Input is a file and its content.
+type Input struct {
+	Filename string
+	Src      []byte
+}
+
+type cacheEntry struct {
+	// Outputs maps output files to hashes of their contents.
+	Outputs  map[string]string `json:"outputs"`
+	Types    []TypeSummary     `json:"types"`
+	Warnings []Diagnostic      `json:"warnings"`
+}
+
+// OpenCache opens the cache of the rewriter under the user cache directory, $XDG_CACHE_HOME on Linux.
+// It returns nil if cfg.Check is set, since checks must not trust previous runs.
+func OpenCache(cfg Config) (*Cache, error) {
+	if cfg.Check {
+		return nil, nil
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	exeHash, err := executableHash()
+	if err != nil {
+		return nil, err
+	}
+	out, err := filepath.Abs(cfg.Out)
+	if err != nil {
+		return nil, err
+	}
+	options, err := json.Marshal(struct {
+		Name        string
+		Write       bool
+		Out         string
+		Companion   string
+		Integers    bool
+		WarnOrphans bool
+	}{cfg.Name, cfg.Write, out, cfg.Companion, cfg.Integers, cfg.WarnOrphans})
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	h.Write(exeHash)
+	h.Write(options)
+	return &Cache{
+		dir:   filepath.Join(base, "go-example-code-generation", "rewrite"),
+		salt:  h.Sum(nil),
+		force: cfg.Force,
+		cfg:   cfg,
+	}, nil
+}
+
+func executableHash() ([]byte, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(exe)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func (c *Cache) path(inputs []Input) string {
+	sorted := slices.Clone(inputs)
+	slices.SortFunc(sorted, func(i, j Input) int { return strings.Compare(i.Filename, j.Filename) })
+	h := sha256.New()
+	h.Write(c.salt)
+	for _, in := range sorted {
+		fmt.Fprintf(h, "%s\x00%d\x00", in.Filename, len(in.Src))
+		h.Write(in.Src)
+	}
+	key := hex.EncodeToString(h.Sum(nil))
+	return filepath.Join(c.dir, key[:2], key)
+}
+
+func hashHex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// Lookup returns actions and warnings recorded for a package consisting of inputs
+// if outputs of the previous run are still on disk.
+func (c *Cache) Lookup(inputs []Input) ([]TypeSummary, []Diagnostic, bool) {
+	if c == nil {
+		return nil, nil, false
+	}
+	var (
+		entry cacheEntry
+		ok    bool
+	)
+	if !c.force {
+		entry, ok = c.lookup(inputs)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !ok {
+		c.misses++
+		return nil, nil, false
+	}
+	c.hits++
+	return entry.Types, entry.Warnings, true
+}
+
+// Hits returns the number of lookups which hit.
+func (c *Cache) Hits() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits
+}
+
+// Misses returns the number of lookups which missed.
+func (c *Cache) Misses() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.misses
+}
+
+func (c *Cache) lookup(inputs []Input) (cacheEntry, bool) {
+	bin, err := os.ReadFile(c.path(inputs))
+	if err != nil {
+		return cacheEntry{}, false
+	}
+	var entry cacheEntry
+	if json.Unmarshal(bin, &entry) != nil {
+		return cacheEntry{}, false
+	}
+	for filename, hash := range entry.Outputs {
+		outPath, err := c.cfg.OutputPath(filename)
+		if err != nil {
+			return cacheEntry{}, false
+		}
+		out, err := os.ReadFile(outPath)
+		if err != nil || hashHex(out) != hash {
+			return cacheEntry{}, false
+		}
+	}
+	return entry, true
+}
+
+// Store records that a package consisting of inputs was rewritten into outputs, taking actions types and reporting warnings.
+// When rewriting in place, the package after rewriting is also recorded, since rewriting is idempotent,
+// unless warnings were reported, whose positions may be moved by rewriting.
+func (c *Cache) Store(inputs, outputs []Input, types []TypeSummary, warnings []Diagnostic) error {
+	if c == nil {
+		return nil
+	}
+	entry := cacheEntry{Outputs: make(map[string]string, len(outputs)), Types: types, Warnings: warnings}
+	for _, out := range outputs {
+		entry.Outputs[out.Filename] = hashHex(out.Src)
+	}
+	err := c.store(inputs, entry)
+	if err != nil || !c.cfg.Write || len(outputs) == 0 || len(warnings) > 0 {
+		return err
+	}
+
+	// Removed blocks are gone from the rewritten package.
+	entry.Types = slices.DeleteFunc(slices.Clone(types), func(t TypeSummary) bool { return t.Action == Removed })
+	rewritten := slices.Clone(inputs)
+	for _, out := range outputs {
+		i := slices.IndexFunc(rewritten, func(in Input) bool { return in.Filename == out.Filename })
+		if i < 0 {
+			rewritten = append(rewritten, out)
+			continue
+		}
+		rewritten[i] = out
+	}
+	return c.store(rewritten, entry)
+}
+
+func (c *Cache) store(inputs []Input, entry cacheEntry) error {
+	bin, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	path := c.path(inputs)
+	err = os.MkdirAll(filepath.Dir(path), fs.ModePerm)
+	if err == nil {
+		err = atomicfile.WriteFile(path, bin, 0o644)
+	}
+	if errors.Is(err, fs.ErrPermission) {
+		// The cache is an optimization; an unwritable cache must not fail the run.
+		return nil
+	}
+	return err
+}
+
+// typesInput returns a pseudo input describing underlying types of types of pkg carrying enum directives.
+// Whether they are enums depends on other packages, so it is looked up along with files of pkg.
+func typesInput(pkg *packages.Package) Input {
+	var buf bytes.Buffer
+	for _, f := range pkg.Syntax {
+		for _, spec := range annotatedTypes(f) {
+			if obj := pkg.TypesInfo.Defs[spec.Name]; obj != nil {
+				fmt.Fprintf(&buf, "%s %s\n", spec.Name.Name, types.TypeString(obj.Type().Underlying(), nil))
+			}
+		}
+	}
+	return Input{Filename: "(types)", Src: buf.Bytes()}
+}
@@ -0,0 +1,106 @@
+package driver
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	filename, companion := filepath.Join(dir, "target.go"), filepath.Join(dir, "target_enum.go")
+	cfg := Config{Name: "test", Write: true}
+
+	cache, err := OpenCache(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputs := []Input{{Filename: filename, Src: []byte("src")}}
+	outputs := []Input{{Filename: filename, Src: []byte("out")}, {Filename: companion, Src: []byte("companion")}}
+	types := []TypeSummary{{Prefix: "enum:", Name: "Enum", File: "target.go", Action: Generated}}
+	warnings := []Diagnostic{{Pos: token.Position{Filename: filename, Offset: 1, Line: 2, Column: 1}, Severity: SeverityWarning, Message: "warning"}}
+
+	if _, _, ok := cache.Lookup(inputs); ok {
+		t.Fatalf("empty cache must not hit")
+	}
+	err = cache.Store(inputs, outputs, types, warnings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := cache.Lookup(inputs); ok {
+		t.Errorf("must not hit while outputs are not on disk")
+	}
+
+	for _, out := range outputs {
+		err = os.WriteFile(out.Filename, out.Src, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	cached, cachedWarnings, ok := cache.Lookup(inputs)
+	if !ok || !reflect.DeepEqual(cached, types) || !reflect.DeepEqual(cachedWarnings, warnings) {
+		t.Errorf("expected hit with %v, %v but got %t, %v, %v", types, warnings, ok, cached, cachedWarnings)
+	}
+	// Positions of warnings are not valid in the rewritten package.
+	if _, _, ok := cache.Lookup([]Input{outputs[1], outputs[0]}); ok {
+		t.Errorf("rewritten package must not hit with warnings")
+	}
+	err = cache.Store(inputs, outputs, types, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The rewritten package includes the created companion file, listed in any order.
+	if _, _, ok := cache.Lookup([]Input{outputs[1], outputs[0]}); !ok {
+		t.Errorf("rewritten package must hit")
+	}
+
+	err = os.WriteFile(companion, []byte("hand-edited"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := cache.Lookup(inputs); ok {
+		t.Errorf("must not hit after an output is edited")
+	}
+
+	noEnum := []Input{{Filename: filename, Src: []byte("no enum")}}
+	err = cache.Store(noEnum, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := cache.Lookup(noEnum); !ok {
+		t.Errorf("package without enums must hit regardless of outputs")
+	}
+
+	// Orphans are warned about instead of removed, so results of the run removing them must not be reused.
+	cfg.WarnOrphans = true
+	warning, err := OpenCache(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := warning.Lookup(noEnum); ok {
+		t.Errorf("must not hit with WarnOrphans toggled")
+	}
+	cfg.WarnOrphans = false
+
+	cfg.Force = true
+	forced, err := OpenCache(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := forced.Lookup(noEnum); ok {
+		t.Errorf("must not hit with Force")
+	}
+	if cache.Hits() != 3 || cache.Misses() != 4 || forced.Misses() != 1 {
+		t.Errorf("unexpected stats: %d hits, %d misses, %d forced misses", cache.Hits(), cache.Misses(), forced.Misses())
+	}
+
+	cfg.Check = true
+	checking, err := OpenCache(cfg)
+	if err != nil || checking != nil {
+		t.Errorf("cache must be disabled in check mode but got %v, %v", checking, err)
+	}
+}
@@ -0,0 +1,102 @@
+package driver
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Severity is the severity of a Diagnostic.
+type Severity string
+
+const (
+	// SeverityError makes the rewriter exit non-zero.
+	SeverityError Severity = "error"
+	// SeverityWarning is reported but does not affect the exit status.
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a problem found in source code, e.g. a malformed directive.
+type Diagnostic struct {
+	Pos      token.Position
+	Severity Severity
+	Message  string
+}
+
+// Diagnostics collects diagnostics reported during a rewriter run.
+type Diagnostics struct {
+	List []Diagnostic
+}
+
+// Errorf reports an error at pos.
+func (d *Diagnostics) Errorf(pos token.Position, format string, args ...any) {
+	d.List = append(d.List, Diagnostic{Pos: pos, Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+}
+
+// Warnf reports a warning at pos.
+func (d *Diagnostics) Warnf(pos token.Position, format string, args ...any) {
+	d.List = append(d.List, Diagnostic{Pos: pos, Severity: SeverityWarning, Message: fmt.Sprintf(format, args...)})
+}
+
+// HasErrors reports whether any error-level diagnostic is collected.
+func (d *Diagnostics) HasErrors() bool {
+	for _, diag := range d.List {
+		if diag.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+type jsonDiagnostic struct {
+	Posn     string   `json:"posn"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// Print writes diagnostics to w, one per line as go vet does, e.g.
+//
+//	target/target.go:3:1: error: unknown directive "enum:varaints"
+//
+// If asJSON is set, they are written as a JSON array of objects with posn, severity and message fields instead.
+// Diagnostics are sorted by position.
+func (d *Diagnostics) Print(w io.Writer, asJSON bool) error {
+	sorted := slices.Clone(d.List)
+	slices.SortStableFunc(sorted, func(i, j Diagnostic) int {
+		if c := cmp.Compare(i.Pos.Filename, j.Pos.Filename); c != 0 {
+			return c
+		}
+		return cmp.Compare(i.Pos.Offset, j.Pos.Offset)
+	})
+	if asJSON {
+		list := make([]jsonDiagnostic, len(sorted))
+		for i, diag := range sorted {
+			list[i] = jsonDiagnostic{Posn: relPosition(diag.Pos), Severity: diag.Severity, Message: diag.Message}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(list)
+	}
+	for _, diag := range sorted {
+		_, err := fmt.Fprintf(w, "%s: %s: %s\n", relPosition(diag.Pos), diag.Severity, diag.Message)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relPosition formats pos with its filename relative to the current directory if it is under there.
+func relPosition(pos token.Position) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, pos.Filename); err == nil && filepath.IsLocal(rel) {
+			pos.Filename = rel
+		}
+	}
+	return pos.String()
+}
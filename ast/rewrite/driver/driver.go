@@ -0,0 +1,301 @@
+// Package driver implements the parts shared by the astutil and dstutil rewriters:
+// flags, package loading, directive diagnostics, output paths, the cache of previous runs
+// and the summary of rewritten enums.
+package driver
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/ngicks/go-example-code-generation/ast/rewrite/directive"
+	"github.com/ngicks/go-example-code-generation/internal/atomicfile"
+	"github.com/ngicks/go-example-code-generation/internal/diff"
+	"golang.org/x/tools/go/packages"
+)
+
+// Config holds options common to the rewriters.
+type Config struct {
+	// Name is the name of the rewriter.
+	Name string
+	// Write makes rewriters overwrite source files in place instead of writing them under Out.
+	Write bool
+	// Out is the directory rewritten files are written into,
+	// keeping their path relative to the current directory.
+	Out string
+	// Patterns are package patterns to load, e.g. ./...
+	Patterns []string
+	// JSON makes diagnostics printed as JSON.
+	JSON bool
+	// Check makes rewriters compare rewritten files to ones on disk instead of writing them.
+	Check bool
+	// Backup makes rewriters keep the original content of each overwritten file as <file>.orig.
+	Backup bool
+	// Force makes rewriters ignore the cache and rewrite every file.
+	Force bool
+	// Jobs is the maximum number of files processed concurrently.
+	Jobs int
+	// Watch makes rewriters keep running after the first run,
+	// rewriting packages again whenever their files change.
+	Watch bool
+	// Poll is the interval to poll files in watch mode.
+	Poll time.Duration
+	// Companion is the suffix of companion files, e.g. _enum.go.
+	// If set, const blocks of enums declared in foo.go are generated into foo<Companion> instead of next to their types.
+	Companion string
+	// Integers makes rewriters accept enums whose underlying type is an integer, numbering their variants by iota.
+	Integers bool
+	// WarnOrphans makes rewriters only warn about generated blocks whose types no longer declare enums,
+	// instead of removing them.
+	WarnOrphans bool
+	// Generators are generators of declarations the rewriter plans, e.g. EnumGenerator.
+	// If empty, only enums are generated, by EnumGenerator with Integers.
+	Generators []Generator
+	// Stdout and Stderr are where results and diagnostics are printed. If nil, os.Stdout and os.Stderr are used.
+	Stdout, Stderr io.Writer
+}
+
+// ParseFlags parses command line flags of a rewriter.
+// Rewritten files are written under defaultOut unless -w is given,
+// and defaultPattern is loaded if no pattern is given.
+func ParseFlags(name string, args []string, defaultOut, defaultPattern string) (Config, error) {
+	cfg := Config{Name: name}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.BoolVar(&cfg.Write, "w", false, "overwrite source files in place")
+	fs.StringVar(&cfg.Out, "out", defaultOut, "directory to write rewritten files into, ignored if -w is set")
+	fs.BoolVar(&cfg.JSON, "json", false, "print diagnostics as JSON")
+	fs.BoolVar(&cfg.Backup, "backup", false, "keep the original content of each overwritten file as <file>.orig")
+	fs.BoolVar(&cfg.Force, "force", false, "rewrite every file, ignoring the cache of previous runs")
+	fs.IntVar(&cfg.Jobs, "j", runtime.GOMAXPROCS(0), "maximum number of files processed concurrently")
+	fs.BoolVar(&cfg.Watch, "watch", false, "keep running and rewrite packages again when their files change")
+	fs.DurationVar(&cfg.Poll, "poll", 500*time.Millisecond, "interval to poll files in watch mode")
+	fs.StringVar(&cfg.Companion, "companion", "", "generate const blocks of enums declared in foo.go into foo<suffix>, e.g. _enum.go, instead of next to their types")
+	fs.BoolVar(&cfg.Integers, "int", false, "also accept enums whose underlying type is an integer, numbering their variants by iota")
+	fs.BoolVar(&cfg.WarnOrphans, "warn-orphans", false, "only warn about generated blocks whose types no longer declare enums instead of removing them")
+	fs.BoolVar(&cfg.Check, "check", false, "print unified diffs of files that would be rewritten instead of writing them, and exit with 1 if any")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s [flags] [packages]\n\nFlags:\n", name)
+		fs.PrintDefaults()
+	}
+	err := fs.Parse(args)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Patterns = fs.Args()
+	if len(cfg.Patterns) == 0 {
+		cfg.Patterns = []string{defaultPattern}
+	}
+	return cfg, nil
+}
+
+// Load loads packages matched by cfg.Patterns with syntax and type information.
+// Packages located under cfg.Out are skipped unless cfg.Write is set,
+// since they are outputs of a previous run.
+func Load(cfg Config) ([]*packages.Package, error) {
+	pkgs, err := packages.Load(
+		&packages.Config{
+			Mode: packages.NeedName |
+				packages.NeedFiles |
+				packages.NeedImports |
+				packages.NeedDeps |
+				packages.NeedTypes |
+				packages.NeedTypesInfo |
+				packages.NeedSyntax,
+		},
+		cfg.Patterns...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return nil, errors.New("packages contain errors")
+	}
+	if cfg.Write {
+		return pkgs, nil
+	}
+
+	out, err := filepath.Abs(cfg.Out)
+	if err != nil {
+		return nil, err
+	}
+	loaded := pkgs[:0]
+	for _, pkg := range pkgs {
+		if len(pkg.GoFiles) > 0 && isUnder(filepath.Dir(pkg.GoFiles[0]), out) {
+			continue
+		}
+		loaded = append(loaded, pkg)
+	}
+	return loaded, nil
+}
+
+func isUnder(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// OutputPath returns the path the rewritten filename is written to.
+func (cfg Config) OutputPath(filename string) (string, error) {
+	if cfg.Write {
+		return filename, nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(wd, filename)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("%s is outside of the current directory: use -w to rewrite it in place", filename)
+	}
+	return filepath.Join(cfg.Out, rel), nil
+}
+
+// WriteFile writes src to the output path of filename, creating directories as needed.
+// The file is replaced atomically, keeping its permission,
+// so that a failure while writing never leaves a truncated source file.
+// If cfg.Backup is set, the existing file is copied to <path>.orig beforehand.
+// Files whose content is unchanged are not written.
+func (cfg Config) WriteFile(filename string, src []byte) error {
+	path, err := cfg.OutputPath(filename)
+	if err != nil {
+		return err
+	}
+	old, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if bytes.Equal(old, src) {
+			return nil
+		}
+		if cfg.Backup {
+			info, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			err = atomicfile.WriteFile(path+".orig", old, info.Mode().Perm())
+			if err != nil {
+				return err
+			}
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		err = os.MkdirAll(filepath.Dir(path), fs.ModePerm)
+		if err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+	return atomicfile.WriteFile(path, src, 0o644)
+}
+
+// Emit writes src to the output path of filename.
+// If cfg.Check is set, it instead prints the unified diff from the file on disk to src into w,
+// and reports an error to diags if they differ.
+func (cfg Config) Emit(w io.Writer, diags *Diagnostics, filename string, src []byte) error {
+	if !cfg.Check {
+		return cfg.WriteFile(filename, src)
+	}
+	path, err := cfg.OutputPath(filename)
+	if err != nil {
+		return err
+	}
+	old, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	d := diff.Unified(path, path, old, src)
+	if d == nil {
+		return nil
+	}
+	diags.Errorf(token.Position{Filename: path}, "generated code is out of date")
+	_, err = w.Write(d)
+	return err
+}
+
+// Action describes what a rewriter did for an annotated type.
+type Action string
+
+const (
+	// Generated means declarations for the type were newly added.
+	Generated Action = "generated"
+	// Updated means existing declarations for the type were replaced by different ones.
+	Updated Action = "updated"
+	// Unchanged means existing declarations for the type were regenerated identically.
+	Unchanged Action = "unchanged"
+	// Removed means orphaned declarations for the type, which no longer has directives, were deleted.
+	Removed Action = "removed"
+	// Cached means the package declaring the type was unchanged since the last run and was skipped.
+	Cached Action = "cached"
+)
+
+// TypeSummary records the action taken for an annotated type.
+type TypeSummary struct {
+	// Prefix is the prefix of the generator, e.g. "enum:".
+	Prefix string `json:"prefix"`
+	Name   string `json:"name"`
+	File   string `json:"file"`
+	Action Action `json:"action"`
+}
+
+// PackageSummary records actions taken in a package.
+type PackageSummary struct {
+	PkgPath string
+	Types   []TypeSummary
+}
+
+// Summary collects actions taken by a rewriter run, in the order of packages.
+type Summary struct {
+	Packages []PackageSummary
+	// Diagnosed tells that errors were reported, e.g. for malformed directives, whose types are not summarized.
+	Diagnosed bool
+}
+
+// Add records t, taken for a type of the package pkgPath.
+func (s *Summary) Add(pkgPath string, t TypeSummary) {
+	if len(s.Packages) == 0 || s.Packages[len(s.Packages)-1].PkgPath != pkgPath {
+		s.Packages = append(s.Packages, PackageSummary{PkgPath: pkgPath})
+	}
+	p := &s.Packages[len(s.Packages)-1]
+	t.File = filepath.Base(t.File)
+	p.Types = append(p.Types, t)
+}
+
+// Print writes s to w, one line per type grouped by package.
+// Types are prefixed by prefixes of their generators other than enum:, e.g. getter:Person.
+func (s *Summary) Print(w io.Writer) error {
+	if len(s.Packages) == 0 {
+		msg := "no directives found"
+		if s.Diagnosed {
+			msg = "no valid directives found"
+		}
+		_, err := fmt.Fprintln(w, msg)
+		return err
+	}
+	for _, p := range s.Packages {
+		_, err := fmt.Fprintln(w, p.PkgPath)
+		if err != nil {
+			return err
+		}
+		for _, t := range p.Types {
+			name := t.Name
+			if t.Prefix != directive.Prefix {
+				name = t.Prefix + name
+			}
+			_, err := fmt.Fprintf(w, "\t%-9s %s (%s)\n", t.Action, name, t.File)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
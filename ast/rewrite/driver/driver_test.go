@@ -0,0 +1,41 @@
+package driver
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSummaryPrint(t *testing.T) {
+	var withTypes Summary
+	withTypes.Add("example.com/target", TypeSummary{Prefix: "enum:", Name: "Status", File: "target.go", Action: Generated})
+	withTypes.Add("example.com/target", TypeSummary{Prefix: "getter:", Name: "Person", File: "target.go", Action: Unchanged})
+
+	for _, tc := range []struct {
+		name     string
+		summary  Summary
+		expected string
+	}{
+		{
+			name:     "types",
+			summary:  withTypes,
+			expected: "example.com/target\n\tgenerated Status (target.go)\n\tunchanged getter:Person (target.go)\n",
+		},
+		{
+			name:     "empty",
+			expected: "no directives found\n",
+		},
+		{
+			name:     "diagnosed",
+			summary:  Summary{Diagnosed: true},
+			expected: "no valid directives found\n",
+		},
+	} {
+		var buf bytes.Buffer
+		if err := tc.summary.Print(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tc.expected {
+			t.Errorf("%s: expected\n%s\nbut got\n%s", tc.name, tc.expected, buf.String())
+		}
+	}
+}
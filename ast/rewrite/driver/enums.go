@@ -0,0 +1,171 @@
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/ngicks/go-example-code-generation/ast/rewrite/directive"
+	"github.com/ngicks/go-example-code-generation/enum"
+	"golang.org/x/tools/go/packages"
+)
+
+// EnumGenerator is the generator of const blocks of enums declared by //enum: directives, and predicates of their excepts.
+// Values of its annotations are enum.EnumParam.
+type EnumGenerator struct {
+	// Integers makes it accept enums whose underlying type is an integer.
+	Integers bool
+}
+
+func (EnumGenerator) Prefix() string {
+	return directive.Prefix
+}
+
+// Find returns enums declared in f as FindEnums does,
+// along with types whose directives are malformed, annotated with nil values.
+func (g EnumGenerator) Find(pkg *packages.Package, f *ast.File, diags *Diagnostics) []Annotation {
+	enums := FindEnums(pkg.Fset, pkg.TypesInfo, f, g.Integers, diags)
+	var found []Annotation
+	for _, spec := range annotatedTypes(f) {
+		a := Annotation{Spec: spec}
+		if param, ok := enums[spec]; ok {
+			a.Value = param
+		}
+		found = append(found, a)
+	}
+	return found
+}
+
+// FindEnums returns params of enums declared by directives in f, keyed by their type spec.
+// Names of params are set to names of types.
+//
+// Directives are read from the doc comment of each type, including types in a grouped declaration.
+// Types are resolved by info, so any defined type whose underlying type is string is accepted,
+// and if integers is set, ones whose underlying type is an integer are accepted as integer enums.
+//
+// Malformed directives, directives on a whole group, on aliases or on types of other underlying types,
+// and invalid params are reported to diags as errors, and directives not attached to a type declaration as warnings.
+func FindEnums(fset *token.FileSet, info *types.Info, f *ast.File, integers bool, diags *Diagnostics) map[*ast.TypeSpec]enum.EnumParam {
+	enums := make(map[*ast.TypeSpec]enum.EnumParam)
+	attached := make(map[*ast.CommentGroup]bool)
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		if gen.Lparen.IsValid() {
+			attached[gen.Doc] = true
+			if _, ok := parseDirectives(fset, gen.Doc, diags); ok {
+				diags.Errorf(fset.Position(gen.Doc.Pos()), "enum directives on a grouped type declaration must be written above each type in the group")
+			}
+		}
+
+		for _, spec := range gen.Specs {
+			spec := spec.(*ast.TypeSpec)
+			doc := SpecDoc(gen, spec)
+			attached[doc] = true
+			param, ok := parseDirectives(fset, doc, diags)
+			if !ok {
+				continue
+			}
+			underlying, err := enumUnderlying(info, spec, integers)
+			if err != nil {
+				diags.Errorf(fset.Position(spec.Name.Pos()), "enum directives on type %s: %v", spec.Name.Name, err)
+				continue
+			}
+			param.Name = spec.Name.Name
+			if underlying != "string" {
+				param.Underlying = underlying
+			}
+			err = param.Validate()
+			var collision *enum.IdentCollisionError
+			if errors.As(err, &collision) {
+				collision.Hint = "add //enum:ident <variant> <Ident> directives to give them distinct identifiers"
+			}
+			if err != nil {
+				diags.Errorf(fset.Position(doc.Pos()), "%v", err)
+				continue
+			}
+			enums[spec] = param
+		}
+	}
+
+	for _, cg := range f.Comments {
+		if attached[cg] {
+			continue
+		}
+		_, found, err := directive.Parse(CommentTexts(cg))
+		if found || err != nil {
+			diags.Warnf(fset.Position(cg.Pos()), "enum directives are not attached to a type declaration")
+		}
+	}
+	return enums
+}
+
+// parseDirectives parses directives in cg, reporting malformed ones to diags.
+// It reports false if cg has no directive or they are malformed.
+func parseDirectives(fset *token.FileSet, cg *ast.CommentGroup, diags *Diagnostics) (enum.EnumParam, bool) {
+	texts := CommentTexts(cg)
+	param, found, err := directive.Parse(texts)
+	if err != nil {
+		var dErr *directive.Error
+		if errors.As(err, &dErr) {
+			diags.Errorf(fset.Position(cg.List[dErr.Comment].Slash+token.Pos(dErr.Offset)), "%v", dErr)
+		} else {
+			diags.Errorf(fset.Position(cg.Pos()), "%v", err)
+		}
+		return enum.EnumParam{}, false
+	}
+	return param, found
+}
+
+// SpecDoc returns the doc comment of spec declared by gen.
+// The doc comment of a type declared without parentheses is held by gen.
+func SpecDoc(gen *ast.GenDecl, spec *ast.TypeSpec) *ast.CommentGroup {
+	if gen.Lparen.IsValid() {
+		return spec.Doc
+	}
+	return gen.Doc
+}
+
+// enumUnderlying returns the name of the underlying type of the type declared by spec, either string or an integer type.
+func enumUnderlying(info *types.Info, spec *ast.TypeSpec, integers bool) (string, error) {
+	obj, ok := info.Defs[spec.Name].(*types.TypeName)
+	if !ok {
+		return "", errors.New("type information is not available")
+	}
+	if obj.IsAlias() {
+		return "", fmt.Errorf("it must be a defined type but is an alias of %s", types.TypeString(types.Unalias(obj.Type()), nil))
+	}
+	if spec.TypeParams != nil {
+		return "", errors.New("it must not have type parameters")
+	}
+	allowed := "string"
+	if integers {
+		allowed = "string or an integer"
+	}
+	underlying := obj.Type().Underlying()
+	basic, ok := underlying.(*types.Basic)
+	switch {
+	case ok && basic.Info()&types.IsString != 0:
+		return "string", nil
+	case ok && integers && basic.Info()&types.IsInteger != 0 && basic.Kind() != types.Uintptr:
+		// Use the name of the kind, so byte and rune are spelled uint8 and int32.
+		return types.Typ[basic.Kind()].Name(), nil
+	}
+	return "", fmt.Errorf("its underlying type must be %s but is %s", allowed, types.TypeString(underlying, nil))
+}
+
+// CommentTexts returns texts of comments in cg, including comment markers.
+func CommentTexts(cg *ast.CommentGroup) []string {
+	if cg == nil {
+		return nil
+	}
+	texts := make([]string, len(cg.List))
+	for i, c := range cg.List {
+		texts[i] = c.Text
+	}
+	return texts
+}
@@ -0,0 +1,174 @@
+package driver
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+
+	"github.com/ngicks/go-example-code-generation/enum"
+)
+
+const enumsSrc = `package target
+
+//enum:variants=foo,"b,ar"
+type A string
+
+//enum:variant=foo
+type B string
+
+//enum:variants=foo,bar
+type C int
+
+//enum:variants=foo,foo
+type D string
+
+//enum:variants=foo
+
+// E is not annotated.
+type E string
+
+type (
+	//enum:variants=foo
+	F string
+	G string
+	//enum:variants=bar
+	H string
+)
+
+//enum:variants=foo
+type (
+	I string
+)
+
+type MyString string
+
+//enum:variants=foo
+type J MyString
+
+//enum:variants=foo
+type K = string
+
+//enum:variants=foo
+type L struct{}
+
+//enum:variants=foo,bar
+//enum:except=NotFoo foo
+type M string
+
+//enum:variants=b-ar,b_ar
+type N string
+
+//enum:variants=b-ar,b_ar
+//enum:ident b-ar BDashAr
+type O string
+`
+
+// typeCheck returns type information of f, which must have no imports.
+func typeCheck(t *testing.T, fset *token.FileSet, files ...*ast.File) *types.Info {
+	t.Helper()
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	// Errors, such as constants declared twice, are tolerated since only definitions of types matter.
+	conf := types.Config{Error: func(error) {}}
+	_, _ = conf.Check(files[0].Name.Name, fset, files, info)
+	return info
+}
+
+func TestFindEnums(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "target.go", enumsSrc, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var diags Diagnostics
+	enums := FindEnums(fset, typeCheck(t, fset, f), f, false, &diags)
+	names := make(map[string]enum.EnumParam)
+	for spec, param := range enums {
+		if spec.Name.Name != param.Name {
+			t.Errorf("param of %s is named %s", spec.Name.Name, param.Name)
+		}
+		names[param.Name] = param
+	}
+	if len(names) != 6 {
+		t.Fatalf("expected enums A, F, H, J, M and O but got %v", names)
+	}
+	if m := names["M"]; !reflect.DeepEqual(m.Excepts, []enum.EnumExceptParam{{ExceptName: "NotFoo", ExcludedValiants: []string{"foo"}}}) {
+		t.Errorf("unexpected excepts of enum M: %#v", m.Excepts)
+	}
+	if o := names["O"]; o.VariantIdent("b-ar") != "OBDashAr" || o.VariantIdent("b_ar") != "OB_ar" {
+		t.Errorf("unexpected identifiers of enum O: %#v", o.Idents)
+	}
+	if a := names["A"]; len(a.Variants) != 2 || a.Variants[1] != "b,ar" {
+		t.Errorf("unexpected enum A: %#v", a)
+	}
+	if f, h := names["F"], names["H"]; !reflect.DeepEqual(f.Variants, []string{"foo"}) || !reflect.DeepEqual(h.Variants, []string{"bar"}) {
+		t.Errorf("unexpected enums F and H in the group: %#v, %#v", f, h)
+	}
+
+	if !diags.HasErrors() {
+		t.Errorf("expected errors")
+	}
+	var buf bytes.Buffer
+	err = diags.Print(&buf, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `target.go:6:8: error: enum:variant must be followed by a space, e.g. //enum:variant foo
+target.go:10:6: error: enum directives on type C: its underlying type must be string but is int
+target.go:12:1: error: enum D: duplicate variant "foo"
+target.go:15:1: warning: enum directives are not attached to a type declaration
+target.go:28:1: error: enum directives on a grouped type declaration must be written above each type in the group
+target.go:39:6: error: enum directives on type K: it must be a defined type but is an alias of string
+target.go:42:6: error: enum directives on type L: its underlying type must be string but is struct{}
+target.go:48:1: error: enum N: variants collide after conversion to identifiers
+	NB_ar: ["b-ar" "b_ar"]
+add //enum:ident <variant> <Ident> directives to give them distinct identifiers
+`
+	if buf.String() != expected {
+		t.Errorf("not equal:\nexpected:\n%s\nactual:\n%s", expected, buf.String())
+	}
+}
+
+func TestFindEnumsIntegers(t *testing.T) {
+	const src = `package target
+
+type Small uint8
+
+//enum:variants=foo,bar
+type C int
+
+//enum:variants=foo
+type S Small
+
+//enum:variants=foo
+type F float64
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "target.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var diags Diagnostics
+	enums := FindEnums(fset, typeCheck(t, fset, f), f, true, &diags)
+	underlying := make(map[string]string)
+	for _, param := range enums {
+		underlying[param.Name] = param.Underlying
+	}
+	expected := map[string]string{"C": "int", "S": "uint8"}
+	if !reflect.DeepEqual(underlying, expected) {
+		t.Errorf("expected %v but got %v", expected, underlying)
+	}
+	var buf bytes.Buffer
+	err = diags.Print(&buf, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedDiag := "target.go:12:6: error: enum directives on type F: its underlying type must be string or an integer but is float64\n"
+	if buf.String() != expectedDiag {
+		t.Errorf("expected\n%s\nbut got\n%s", expectedDiag, buf.String())
+	}
+}
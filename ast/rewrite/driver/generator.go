@@ -0,0 +1,107 @@
+package driver
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Generator finds types annotated by directives of its prefix, for which a rewriter generates declarations.
+//
+// Declarations generated for a type are marked with
+//
+//	//<prefix>generated_for=Type
+//
+// and planned alike for every generator: new ones are inserted after the type declaration,
+// or appended to the companion file if Config.Companion is set,
+// existing ones are replaced wherever they are in the package, duplicates are removed,
+// and ones whose types no longer have directives of the prefix are removed or warned about.
+type Generator interface {
+	// Prefix is the prefix of directives, e.g. "enum:", without the leading "//".
+	Prefix() string
+	// Find returns types declared in f, a file of pkg, which have directives of the prefix, in declaration order.
+	// Problems in directives are reported to diags.
+	Find(pkg *packages.Package, f *ast.File, diags *Diagnostics) []Annotation
+}
+
+// Annotation is a type annotated by directives of a generator.
+type Annotation struct {
+	Spec *ast.TypeSpec
+	// Value describes what is generated for the type, e.g. enum.EnumParam, and is passed to the rewriter.
+	// It is nil if directives are malformed, in which case declarations generated before are kept until they are fixed.
+	Value any
+}
+
+// Generation is what a rewriter generates for an annotated type.
+type Generation struct {
+	// Prefix is the prefix of the generator.
+	Prefix string
+	// Spec is the annotated type, which may be declared in a file other than the one rewritten.
+	Spec  *ast.TypeSpec
+	Value any
+}
+
+// Marker returns the comment marking declarations generated for typeName by the generator of prefix.
+func Marker(prefix, typeName string) string {
+	return "//" + prefix + "generated_for=" + typeName
+}
+
+// generatedFor returns the type name of the marker of prefix in cg.
+func generatedFor(cg *ast.CommentGroup, prefix string) (string, bool) {
+	for _, text := range CommentTexts(cg) {
+		if name, ok := strings.CutPrefix(text, Marker(prefix, "")); ok {
+			return strings.TrimSpace(name), true
+		}
+	}
+	return "", false
+}
+
+// TypeDirectives are directives of a prefix in the doc comment of a type.
+type TypeDirectives struct {
+	Spec     *ast.TypeSpec
+	Comments []*ast.Comment
+}
+
+// FindDirectives returns types declared in f whose doc comments have comments starting with //<prefix>,
+// other than markers, in declaration order.
+// It helps generators whose directives need no parser of their own.
+func FindDirectives(f *ast.File, prefix string) []TypeDirectives {
+	var found []TypeDirectives
+	for _, spec := range typeSpecs(f) {
+		var comments []*ast.Comment
+		if doc := SpecDoc(spec.gen, spec.TypeSpec); doc != nil {
+			for _, c := range doc.List {
+				if strings.HasPrefix(c.Text, "//"+prefix) && !strings.HasPrefix(c.Text, Marker(prefix, "")) {
+					comments = append(comments, c)
+				}
+			}
+		}
+		if len(comments) > 0 {
+			found = append(found, TypeDirectives{Spec: spec.TypeSpec, Comments: comments})
+		}
+	}
+	return found
+}
+
+// declaredSpec is a type spec along with the declaration holding it.
+type declaredSpec struct {
+	*ast.TypeSpec
+	gen *ast.GenDecl
+}
+
+// typeSpecs returns type specs declared at the top level of f in order.
+func typeSpecs(f *ast.File) []declaredSpec {
+	var specs []declaredSpec
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			specs = append(specs, declaredSpec{spec.(*ast.TypeSpec), gen})
+		}
+	}
+	return specs
+}
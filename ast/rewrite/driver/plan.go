@@ -0,0 +1,253 @@
+package driver
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"path/filepath"
+	"strings"
+
+	"github.com/ngicks/go-example-code-generation/ast/rewrite/directive"
+	"golang.org/x/tools/go/packages"
+)
+
+// FilePlan describes how a rewriter edits generated declarations of a file.
+type FilePlan struct {
+	// Replace maps the first of existing declarations generated for each type to what they are regenerated from.
+	// Generated declarations replace it, and the rest of the existing ones are in Remove.
+	Replace map[ast.Decl]Generation
+	// Remove lists generated declarations to delete since their types are generated into another file,
+	// they follow the first declaration generated for the same type, or they are orphaned.
+	Remove []ast.Decl
+	// InsertAfter maps type specs declared in the file to generations whose declarations are inserted after them,
+	// in the order of generators.
+	InsertAfter map[*ast.TypeSpec][]Generation
+	// Append lists generations whose declarations are appended to the end of the file, in order.
+	Append []Generation
+}
+
+func (p *FilePlan) empty() bool {
+	return len(p.Replace) == 0 && len(p.Remove) == 0 && len(p.InsertAfter) == 0 && len(p.Append) == 0
+}
+
+// GeneratedDecl is a declaration marked by //<prefix>generated_for=Type.
+type GeneratedDecl struct {
+	Prefix string
+	Type   string
+	Decl   ast.Decl
+}
+
+// GeneratedDecls returns declarations in f generated by generators of prefixes, in the order of declarations.
+func GeneratedDecls(f *ast.File, prefixes []string) []GeneratedDecl {
+	var found []GeneratedDecl
+	for _, decl := range f.Decls {
+		for _, prefix := range prefixes {
+			if name, ok := generatedFor(declDoc(decl), prefix); ok {
+				found = append(found, GeneratedDecl{Prefix: prefix, Type: name, Decl: decl})
+				break
+			}
+		}
+	}
+	return found
+}
+
+// declDoc returns the doc comment of decl.
+func declDoc(decl ast.Decl) *ast.CommentGroup {
+	switch x := decl.(type) {
+	case *ast.GenDecl:
+		return x.Doc
+	case *ast.FuncDecl:
+		return x.Doc
+	}
+	return nil
+}
+
+// generators returns cfg.Generators, or the generator of enums if none is set.
+func (cfg Config) generators() []Generator {
+	if len(cfg.Generators) == 0 {
+		return []Generator{EnumGenerator{Integers: cfg.Integers}}
+	}
+	return cfg.Generators
+}
+
+func prefixes(generators []Generator) []string {
+	prefixes := make([]string, len(generators))
+	for i, g := range generators {
+		prefixes[i] = g.Prefix()
+	}
+	return prefixes
+}
+
+// CompanionPath returns the companion file of filename, into which blocks of enums declared in filename are generated,
+// or "" if cfg.Companion is not set or filename is a companion itself.
+func (cfg Config) CompanionPath(filename string) string {
+	if cfg.Companion == "" || strings.HasSuffix(filename, cfg.Companion) {
+		return ""
+	}
+	return strings.TrimSuffix(filename, ".go") + cfg.Companion
+}
+
+// plannedFile is a file of a package to be rewritten by its plan.
+type plannedFile struct {
+	filename string
+	f        *ast.File
+	plan     *FilePlan
+}
+
+// planPackage decides where declarations generated for types annotated in pkg go.
+// Existing declarations are replaced wherever they are in the package, unless cfg.Companion directs them into the companion file.
+// Otherwise new ones are inserted after the type declaration, or appended to the companion file which is created as needed.
+// Orphaned declarations, whose types no longer have directives, are removed, or only reported if cfg.WarnOrphans is set.
+// It returns files to rewrite in the order of pkg.Syntax followed by created companions, and actions taken for types.
+func planPackage(cfg Config, pkg *packages.Package, diags *Diagnostics) ([]plannedFile, []TypeSummary, error) {
+	type key struct {
+		prefix, typeName string
+	}
+	type located struct {
+		file int
+		decl GeneratedDecl
+	}
+	var (
+		generators = cfg.generators()
+		files      []plannedFile
+		byName     = make(map[string]int)
+		all        []located
+		existing   = make(map[key][]located)
+		annotated  = make(map[key]bool)
+		// found holds annotations of each file by each generator.
+		found = make([][][]Annotation, len(pkg.Syntax))
+	)
+	for i, f := range pkg.Syntax {
+		filename := pkg.Fset.Position(f.FileStart).Filename
+		files = append(files, plannedFile{filename: filename, f: f, plan: &FilePlan{}})
+		byName[filename] = i
+		for _, d := range GeneratedDecls(f, prefixes(generators)) {
+			k := key{d.Prefix, d.Type}
+			all = append(all, located{file: i, decl: d})
+			existing[k] = append(existing[k], located{file: i, decl: d})
+		}
+		found[i] = make([][]Annotation, len(generators))
+		for j, g := range generators {
+			found[i][j] = g.Find(pkg, f, diags)
+			for _, a := range found[i][j] {
+				annotated[key{g.Prefix(), a.Spec.Name.Name}] = true
+			}
+		}
+	}
+
+	var types []TypeSummary
+	for i := range pkg.Syntax {
+		filename := files[i].filename
+		for j, g := range generators {
+			for _, a := range found[i][j] {
+				if a.Value == nil {
+					continue
+				}
+				gen := Generation{Prefix: g.Prefix(), Spec: a.Spec, Value: a.Value}
+				olds := existing[key{g.Prefix(), a.Spec.Name.Name}]
+				action := Generated
+				if len(olds) > 0 {
+					action = Updated
+				}
+				types = append(types, TypeSummary{Prefix: g.Prefix(), Name: a.Spec.Name.Name, File: filepath.Base(filename), Action: action})
+
+				keep := -1
+				companion := cfg.CompanionPath(filename)
+				if companion == "" {
+					if len(olds) > 0 {
+						keep = 0
+					} else {
+						files[i].plan.insertAfter(a.Spec, gen)
+					}
+				} else {
+					for k, l := range olds {
+						if files[l.file].filename == companion {
+							keep = k
+							break
+						}
+					}
+					if keep < 0 {
+						k, ok := byName[companion]
+						if !ok {
+							created, err := newCompanion(pkg, companion)
+							if err != nil {
+								return nil, nil, err
+							}
+							k = len(files)
+							files = append(files, plannedFile{filename: companion, f: created, plan: &FilePlan{}})
+							byName[companion] = k
+						}
+						files[k].plan.Append = append(files[k].plan.Append, gen)
+					}
+				}
+				for k, l := range olds {
+					plan := files[l.file].plan
+					if k == keep {
+						if plan.Replace == nil {
+							plan.Replace = make(map[ast.Decl]Generation)
+						}
+						plan.Replace[l.decl.Decl] = gen
+					} else {
+						plan.Remove = append(plan.Remove, l.decl.Decl)
+					}
+				}
+			}
+		}
+	}
+
+	// Declarations generated for types no longer having directives are orphaned.
+	// Types whose directives are malformed keep their declarations until fixed.
+	removed := make(map[key]bool)
+	for _, l := range all {
+		k := key{l.decl.Prefix, l.decl.Type}
+		if annotated[k] {
+			continue
+		}
+		file := files[l.file]
+		if cfg.WarnOrphans {
+			diags.Warnf(
+				pkg.Fset.Position(l.decl.Decl.Pos()),
+				"generated declaration for %s is orphaned: no type in the package declares %s directives for it",
+				l.decl.Type, strings.TrimSuffix(l.decl.Prefix, ":"),
+			)
+			continue
+		}
+		file.plan.Remove = append(file.plan.Remove, l.decl.Decl)
+		if !removed[k] {
+			removed[k] = true
+			types = append(types, TypeSummary{Prefix: l.decl.Prefix, Name: l.decl.Type, File: filepath.Base(file.filename), Action: Removed})
+		}
+	}
+
+	planned := files[:0]
+	for _, file := range files {
+		if !file.plan.empty() {
+			planned = append(planned, file)
+		}
+	}
+	return planned, types, nil
+}
+
+func (p *FilePlan) insertAfter(spec *ast.TypeSpec, gen Generation) {
+	if p.InsertAfter == nil {
+		p.InsertAfter = make(map[*ast.TypeSpec][]Generation)
+	}
+	p.InsertAfter[spec] = append(p.InsertAfter[spec], gen)
+}
+
+// annotatedTypes returns specs of types in f whose doc comments contain enum directives, including malformed ones.
+func annotatedTypes(f *ast.File) []*ast.TypeSpec {
+	var specs []*ast.TypeSpec
+	for _, spec := range typeSpecs(f) {
+		if _, found, err := directive.Parse(CommentTexts(SpecDoc(spec.gen, spec.TypeSpec))); found || err != nil {
+			specs = append(specs, spec.TypeSpec)
+		}
+	}
+	return specs
+}
+
+// newCompanion parses an empty companion file of pkg into pkg.Fset.
+func newCompanion(pkg *packages.Package, filename string) (*ast.File, error) {
+	src := fmt.Sprintf("// Code generated by enum rewriter. DO NOT EDIT.\n\npackage %s\n", pkg.Name)
+	return parser.ParseFile(pkg.Fset, filename, src, parser.ParseComments)
+}
@@ -0,0 +1,208 @@
+package driver
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ngicks/go-example-code-generation/enum"
+	"golang.org/x/tools/go/packages"
+)
+
+const planTypesSrc = `package target
+
+//enum:variants=foo
+type A string
+
+//enum:variants=foo
+type B string
+`
+
+const planBlocksSrc = `package target
+
+//enum:generated_for=A
+const (
+	AFoo A = "foo"
+)
+
+//enum:generated_for=A
+const (
+	AFoo A = "foo"
+)
+
+//enum:generated_for=Gone
+const (
+	GoneFoo = "foo"
+)
+`
+
+func planTestPackage(t *testing.T) *packages.Package {
+	t.Helper()
+	pkg := &packages.Package{Name: "target", PkgPath: "example.com/target", Fset: token.NewFileSet()}
+	for _, file := range []struct{ name, src string }{{"types.go", planTypesSrc}, {"blocks.go", planBlocksSrc}} {
+		f, err := parser.ParseFile(pkg.Fset, filepath.Join("/src", file.name), file.src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkg.Syntax = append(pkg.Syntax, f)
+	}
+	pkg.TypesInfo = typeCheck(t, pkg.Fset, pkg.Syntax...)
+	return pkg
+}
+
+func TestPlanPackage(t *testing.T) {
+	pkg := planTestPackage(t)
+	blocks := GeneratedDecls(pkg.Syntax[1], []string{"enum:"})
+	var diags Diagnostics
+	planned, types, err := planPackage(Config{}, pkg, &diags)
+	if err != nil || len(diags.List) > 0 {
+		t.Fatalf("unexpected error %v, %v", err, diags.List)
+	}
+	expectedTypes := []TypeSummary{
+		{Prefix: "enum:", Name: "A", File: "types.go", Action: Updated},
+		{Prefix: "enum:", Name: "B", File: "types.go", Action: Generated},
+		{Prefix: "enum:", Name: "Gone", File: "blocks.go", Action: Removed},
+	}
+	if !reflect.DeepEqual(types, expectedTypes) {
+		t.Errorf("expected %v but got %v", expectedTypes, types)
+	}
+	if len(planned) != 2 {
+		t.Fatalf("expected both files planned but got %d", len(planned))
+	}
+	typesPlan, blocksPlan := planned[0].plan, planned[1].plan
+	if len(typesPlan.InsertAfter) != 1 || len(typesPlan.Replace)+len(typesPlan.Remove)+len(typesPlan.Append) != 0 {
+		t.Errorf("expected a block for B inserted into types.go but got %+v", typesPlan)
+	}
+	for spec, gens := range typesPlan.InsertAfter {
+		if spec.Name.Name != "B" || len(gens) != 1 || gens[0].Spec != spec {
+			t.Errorf("expected a block for B but got %+v after %s", gens, spec.Name.Name)
+		}
+	}
+	if gen, ok := blocksPlan.Replace[blocks[0].Decl]; !ok || gen.Spec.Name.Name != "A" || len(blocksPlan.Replace) != 1 {
+		t.Errorf("expected the first block of A replaced but got %+v", blocksPlan.Replace)
+	} else if param, ok := gen.Value.(enum.EnumParam); !ok || param.Name != "A" {
+		t.Errorf("expected the block of A regenerated from its param but got %+v", gen.Value)
+	}
+	if !reflect.DeepEqual(blocksPlan.Remove, []ast.Decl{blocks[1].Decl, blocks[2].Decl}) {
+		t.Errorf("expected the duplicate block of A and the orphaned block removed but got %v", blocksPlan.Remove)
+	}
+}
+
+func TestPlanPackageCompanion(t *testing.T) {
+	pkg := planTestPackage(t)
+	var diags Diagnostics
+	planned, _, err := planPackage(Config{Companion: "_enum.go"}, pkg, &diags)
+	if err != nil || len(diags.List) > 0 {
+		t.Fatalf("unexpected error %v, %v", err, diags.List)
+	}
+	if len(planned) != 2 {
+		t.Fatalf("expected blocks.go and the companion planned but got %d", len(planned))
+	}
+	if planned[0].filename != "/src/blocks.go" || len(planned[0].plan.Remove) != 3 {
+		t.Errorf("expected all blocks removed from blocks.go but got %s, %+v", planned[0].filename, planned[0].plan)
+	}
+	companion := planned[1]
+	if companion.filename != "/src/types_enum.go" || companion.f.Name.Name != "target" {
+		t.Errorf("unexpected companion %s of package %s", companion.filename, companion.f.Name.Name)
+	}
+	var names []string
+	for _, gen := range companion.plan.Append {
+		names = append(names, gen.Spec.Name.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"A", "B"}) {
+		t.Errorf("expected A and B appended to the companion but got %v", names)
+	}
+}
+
+func TestPlanPackageWarnOrphans(t *testing.T) {
+	pkg := planTestPackage(t)
+	var diags Diagnostics
+	planned, types, err := planPackage(Config{WarnOrphans: true}, pkg, &diags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, typ := range types {
+		if typ.Action == Removed {
+			t.Errorf("orphaned block must not be removed but got %v", types)
+		}
+	}
+	if len(planned) != 2 || len(planned[1].plan.Remove) != 1 {
+		t.Errorf("expected only the duplicate block removed but got %+v", planned[1].plan)
+	}
+	var buf bytes.Buffer
+	err = diags.Print(&buf, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "/src/blocks.go:14:1: warning: generated declaration for Gone is orphaned: no type in the package declares enum directives for it\n"
+	if buf.String() != expected || diags.HasErrors() {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, buf.String())
+	}
+}
+
+// testGenerator annotates types having //test: directives with their comments.
+type testGenerator struct{}
+
+func (testGenerator) Prefix() string {
+	return "test:"
+}
+
+func (testGenerator) Find(pkg *packages.Package, f *ast.File, diags *Diagnostics) []Annotation {
+	var found []Annotation
+	for _, d := range FindDirectives(f, "test:") {
+		if d.Comments[0].Text == "//test:malformed" {
+			diags.Errorf(pkg.Fset.Position(d.Comments[0].Pos()), "malformed")
+			found = append(found, Annotation{Spec: d.Spec})
+			continue
+		}
+		found = append(found, Annotation{Spec: d.Spec, Value: d.Comments})
+	}
+	return found
+}
+
+func TestPlanPackageGenerators(t *testing.T) {
+	pkg := planTestPackage(t)
+	for _, file := range []struct{ name, src string }{
+		{"struct.go", "package target\n\n//test:foo\ntype S struct{}\n\n//test:malformed\ntype M struct{}\n"},
+		{"other.go", "package target\n\n//test:generated_for=S\nfunc (S) F() {}\n\n//test:generated_for=M\nfunc (M) F() {}\n\n//test:generated_for=A\nfunc (A) F() {}\n"},
+	} {
+		f, err := parser.ParseFile(pkg.Fset, filepath.Join("/src", file.name), file.src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkg.Syntax = append(pkg.Syntax, f)
+	}
+	other := GeneratedDecls(pkg.Syntax[3], []string{"enum:", "test:"})
+	var diags Diagnostics
+	planned, types, err := planPackage(Config{Generators: []Generator{EnumGenerator{}, testGenerator{}}}, pkg, &diags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags.List) != 1 || diags.List[0].Message != "malformed" {
+		t.Errorf("expected the malformed directive reported but got %v", diags.List)
+	}
+	expectedTypes := []TypeSummary{
+		{Prefix: "enum:", Name: "A", File: "types.go", Action: Updated},
+		{Prefix: "enum:", Name: "B", File: "types.go", Action: Generated},
+		{Prefix: "test:", Name: "S", File: "struct.go", Action: Updated},
+		{Prefix: "enum:", Name: "Gone", File: "blocks.go", Action: Removed},
+		{Prefix: "test:", Name: "A", File: "other.go", Action: Removed},
+	}
+	if !reflect.DeepEqual(types, expectedTypes) {
+		t.Errorf("expected %v but got %v", expectedTypes, types)
+	}
+	if len(planned) != 3 || planned[2].filename != "/src/other.go" {
+		t.Fatalf("expected other.go planned but got %+v", planned)
+	}
+	otherPlan := planned[2].plan
+	if gen, ok := otherPlan.Replace[other[0].Decl]; !ok || gen.Prefix != "test:" || gen.Spec.Name.Name != "S" || len(otherPlan.Replace) != 1 {
+		t.Errorf("expected the method of S replaced across files but got %+v", otherPlan.Replace)
+	}
+	if !reflect.DeepEqual(otherPlan.Remove, []ast.Decl{other[2].Decl}) {
+		t.Errorf("expected only the orphaned method of A removed but got %v", otherPlan.Remove)
+	}
+}
@@ -0,0 +1,303 @@
+package driver
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// RewriteFunc rewrites f, a file of pkg, into src by editing generated declarations as plan describes.
+// f may be a companion file created by the driver, which is not in pkg.Syntax.
+// Declarations which fail to be generated are reported to diags, keeping ones generated before.
+//
+// It is called concurrently for different files, so it must not modify state shared between files.
+type RewriteFunc func(pkg *packages.Package, f *ast.File, plan FilePlan, diags *Diagnostics) (src []byte, err error)
+
+// Run loads packages of cfg and rewrites their files declaring enums by rewrite.
+// It prints diagnostics to stderr, diffs and the summary to stdout,
+// and returns the exit status of the rewriter, which is 1 if an error is reported.
+//
+// If cfg.Watch is set, it then keeps rewriting packages whose files change until interrupted.
+func Run(cfg Config, rewrite RewriteFunc) int {
+	if cfg.Watch && cfg.Check {
+		fmt.Fprintf(cfg.stderr(), "%s: -watch and -check are mutually exclusive\n", cfg.Name)
+		return 2
+	}
+	pkgs, err := Load(cfg)
+	if err != nil {
+		fmt.Fprintf(cfg.stderr(), "%s: %v\n", cfg.Name, err)
+		return 1
+	}
+	code := Process(cfg, pkgs, rewrite)
+	if !cfg.Watch {
+		return code
+	}
+	return watchLoop(cfg, pkgs, rewrite)
+}
+
+// pkgResult is the outcome of processing a package, buffered to be printed in order.
+type pkgResult struct {
+	pkg    *packages.Package
+	inputs []Input
+	cached bool
+	types  []TypeSummary
+	diags  Diagnostics
+	files  []fileResult
+	err    error
+}
+
+// fileResult is the outcome of rewriting a file.
+type fileResult struct {
+	planned plannedFile
+	out     []byte
+	// oldBlocks and newBlocks map markers of generated declarations to their sources
+	// in the file on disk and in out respectively.
+	oldBlocks, newBlocks map[string]string
+	diff                 bytes.Buffer
+	diags                Diagnostics
+	err                  error
+}
+
+// Process rewrites files of pkgs as Run does.
+// Packages are planned, and then their files are rewritten, by up to cfg.Jobs goroutines,
+// and results are printed in the order of pkgs and their files.
+func Process(cfg Config, pkgs []*packages.Package, rewrite RewriteFunc) int {
+	stdout, stderr := cfg.stdout(), cfg.stderr()
+	cache, err := OpenCache(cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: cache disabled: %v\n", cfg.Name, err)
+	}
+
+	results := make([]*pkgResult, len(pkgs))
+	parallel(cfg.Jobs, len(pkgs), func(i int) {
+		results[i] = planResult(cfg, cache, pkgs[i])
+	})
+
+	type job struct {
+		pkg  *packages.Package
+		file *fileResult
+	}
+	var jobs []job
+	for _, r := range results {
+		for i := range r.files {
+			jobs = append(jobs, job{r.pkg, &r.files[i]})
+		}
+	}
+	parallel(cfg.Jobs, len(jobs), func(i int) {
+		j := jobs[i]
+		j.file.out, j.file.err = rewrite(j.pkg, j.file.planned.f, *j.file.planned.plan, &j.file.diags)
+		if j.file.err != nil {
+			j.file.err = fmt.Errorf("%s: %w", j.file.planned.filename, j.file.err)
+			return
+		}
+		// Blocks on disk must be read before Emit overwrites them.
+		j.file.oldBlocks, j.file.newBlocks = cfg.generatedSources(j.file.planned.filename, j.file.out)
+		j.file.err = cfg.Emit(&j.file.diff, &j.file.diags, j.file.planned.filename, j.file.out)
+	})
+
+	var (
+		summary Summary
+		diags   Diagnostics
+		failed  bool
+	)
+	report := func(err error) {
+		fmt.Fprintf(stderr, "%s: %v\n", cfg.Name, err)
+		failed = true
+	}
+	for _, r := range results {
+		r.compareBlocks()
+		for _, t := range r.types {
+			if r.cached {
+				t.Action = Cached
+			}
+			summary.Add(r.pkg.PkgPath, t)
+		}
+		diags.List = append(diags.List, r.diags.List...)
+		if r.err != nil {
+			report(r.err)
+			continue
+		}
+		var (
+			outputs  []Input
+			warnings = slices.Clone(r.diags.List)
+			ok       = !r.diags.HasErrors()
+		)
+		for i := range r.files {
+			file := &r.files[i]
+			diags.List = append(diags.List, file.diags.List...)
+			warnings = append(warnings, file.diags.List...)
+			_, err := stdout.Write(file.diff.Bytes())
+			if err != nil {
+				panic(err)
+			}
+			if file.err != nil {
+				report(file.err)
+			}
+			ok = ok && file.err == nil && !file.diags.HasErrors()
+			outputs = append(outputs, Input{Filename: file.planned.filename, Src: file.out})
+		}
+		if r.cached || !ok {
+			// Errors must be reported again in the next run.
+			continue
+		}
+		err := cache.Store(r.inputs, outputs, r.types, warnings)
+		if err != nil {
+			report(err)
+		}
+	}
+
+	err = diags.Print(stderr, cfg.JSON)
+	if err != nil {
+		panic(err)
+	}
+	summary.Diagnosed = diags.HasErrors()
+	err = summary.Print(stdout)
+	if err != nil {
+		panic(err)
+	}
+	if cache != nil {
+		fmt.Fprintf(stdout, "cache: %d hits, %d misses\n", cache.Hits(), cache.Misses())
+	}
+	if failed || diags.HasErrors() {
+		return 1
+	}
+	return 0
+}
+
+// compareBlocks corrects generated and updated actions of r by comparing regenerated declarations to ones on disk,
+// so that they tell what is actually written: a type whose declarations are identical is reported as Unchanged,
+// and one whose declarations exist on disk, e.g. in the output of the previous run, is reported as Updated.
+func (r *pkgResult) compareBlocks() {
+	oldBlocks, newBlocks := make(map[string]string), make(map[string]string)
+	for _, file := range r.files {
+		if file.err != nil {
+			return
+		}
+		maps.Copy(oldBlocks, file.oldBlocks)
+		maps.Copy(newBlocks, file.newBlocks)
+	}
+	for i, t := range r.types {
+		if t.Action != Generated && t.Action != Updated {
+			continue
+		}
+		marker := Marker(t.Prefix, t.Name)
+		old, ok := oldBlocks[marker]
+		switch {
+		case !ok:
+		case old == newBlocks[marker]:
+			r.types[i].Action = Unchanged
+		default:
+			r.types[i].Action = Updated
+		}
+	}
+}
+
+// generatedSources returns sources of generated declarations, by their markers,
+// in the file at the output path of filename and in src, the rewritten content of filename.
+func (cfg Config) generatedSources(filename string, src []byte) (oldBlocks, newBlocks map[string]string) {
+	prefixes := prefixes(cfg.generators())
+	if path, err := cfg.OutputPath(filename); err == nil {
+		if old, err := os.ReadFile(path); err == nil {
+			oldBlocks = blockSources(path, old, prefixes)
+		}
+	}
+	return oldBlocks, blockSources(filename, src, prefixes)
+}
+
+// blockSources returns sources of declarations in src generated by generators of prefixes, including their doc comments,
+// by their markers. Sources of declarations generated for the same type are joined by an empty line.
+// It returns nil if src can not be parsed.
+func blockSources(filename string, src []byte, prefixes []string) map[string]string {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil
+	}
+	sources := make(map[string]string)
+	for _, d := range GeneratedDecls(f, prefixes) {
+		start := d.Decl.Pos()
+		if doc := declDoc(d.Decl); doc != nil {
+			start = doc.Pos()
+		}
+		marker := Marker(d.Prefix, d.Type)
+		if prev, ok := sources[marker]; ok {
+			sources[marker] = prev + "\n\n"
+		}
+		sources[marker] += string(src[fset.Position(start).Offset:fset.Position(d.Decl.End()).Offset])
+	}
+	return sources
+}
+
+// planResult reads files of pkg and plans rewriting them unless the cache hits.
+func planResult(cfg Config, cache *Cache, pkg *packages.Package) *pkgResult {
+	r := &pkgResult{pkg: pkg}
+	for _, f := range pkg.Syntax {
+		filename := pkg.Fset.Position(f.FileStart).Filename
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			r.err = err
+			return r
+		}
+		r.inputs = append(r.inputs, Input{Filename: filename, Src: src})
+	}
+	r.inputs = append(r.inputs, typesInput(pkg))
+	if types, warnings, ok := cache.Lookup(r.inputs); ok {
+		r.cached = true
+		r.types = types
+		r.diags.List = warnings
+		return r
+	}
+
+	planned, types, err := planPackage(cfg, pkg, &r.diags)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	r.types = types
+	r.files = make([]fileResult, len(planned))
+	for i, p := range planned {
+		r.files[i].planned = p
+	}
+	return r
+}
+
+// parallel calls fn with 0 to n-1 using up to jobs goroutines and waits for them.
+func parallel(jobs, n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(jobs, 1))
+	for i := range n {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}
+
+func (cfg Config) stdout() io.Writer {
+	if cfg.Stdout != nil {
+		return cfg.Stdout
+	}
+	return os.Stdout
+}
+
+func (cfg Config) stderr() io.Writer {
+	if cfg.Stderr != nil {
+		return cfg.Stderr
+	}
+	return os.Stderr
+}
@@ -0,0 +1,145 @@
+package driver
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestCompareBlocks(t *testing.T) {
+	old := blockSources("old.go", []byte(`package target
+
+//enum:generated_for=A
+const (
+	AFoo A = "foo"
+)
+
+//enum:generated_for=B
+const (
+	BFoo B = "foo"
+)
+
+//enum:generated_for=Gone
+const (
+	GoneFoo = "foo"
+)
+`), []string{"enum:"})
+	r := &pkgResult{
+		types: []TypeSummary{
+			{Prefix: "enum:", Name: "A", Action: Updated},
+			{Prefix: "enum:", Name: "B", Action: Updated},
+			{Prefix: "enum:", Name: "C", Action: Generated},
+			{Prefix: "enum:", Name: "D", Action: Generated},
+			{Prefix: "enum:", Name: "Gone", Action: Removed},
+		},
+		files: []fileResult{
+			{
+				oldBlocks: old,
+				newBlocks: map[string]string{
+					"//enum:generated_for=A": "//enum:generated_for=A\nconst (\n\tAFoo A = \"foo\"\n)",
+					"//enum:generated_for=B": "//enum:generated_for=B\nconst (\n\tBBar B = \"bar\"\n)",
+				},
+			},
+			{
+				oldBlocks: map[string]string{"//enum:generated_for=C": "//enum:generated_for=C\nconst CFoo C = \"foo\""},
+				newBlocks: map[string]string{
+					"//enum:generated_for=C": "//enum:generated_for=C\nconst (\n\tCFoo C = \"foo\"\n)",
+					"//enum:generated_for=D": "//enum:generated_for=D\nconst (\n\tDFoo D = \"foo\"\n)",
+				},
+			},
+		},
+	}
+	r.compareBlocks()
+	expected := []TypeSummary{
+		{Prefix: "enum:", Name: "A", Action: Unchanged},
+		{Prefix: "enum:", Name: "B", Action: Updated},
+		{Prefix: "enum:", Name: "C", Action: Updated},
+		{Prefix: "enum:", Name: "D", Action: Generated},
+		{Prefix: "enum:", Name: "Gone", Action: Removed},
+	}
+	if !reflect.DeepEqual(r.types, expected) {
+		t.Errorf("expected %v but got %v", expected, r.types)
+	}
+}
+
+func TestBlockSources(t *testing.T) {
+	src := `package target
+
+//enum:generated_for=A
+const (
+	AFoo A = "foo"
+)
+
+// F is generated.
+//
+//test:generated_for=A
+func (A) F() {}
+
+//test:generated_for=A
+func (A) G() {}
+`
+	expected := map[string]string{
+		"//enum:generated_for=A": "//enum:generated_for=A\nconst (\n\tAFoo A = \"foo\"\n)",
+		"//test:generated_for=A": "// F is generated.\n//\n//test:generated_for=A\nfunc (A) F() {}\n\n//test:generated_for=A\nfunc (A) G() {}",
+	}
+	if got := blockSources("src.go", []byte(src), []string{"enum:", "test:"}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q but got %q", expected, got)
+	}
+}
+
+func TestProcessCachesWarnings(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	filename := filepath.Join(t.TempDir(), "target.go")
+	// The enum block is up to date, and the directive on the function is only warned about.
+	src := `package target
+
+//enum:variants=foo
+type A string
+
+//enum:generated_for=A
+const (
+	AFoo A = "foo"
+)
+
+//enum:variants=foo
+func f() {}
+`
+	err := os.WriteFile(filename, []byte(src), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := &packages.Package{Name: "target", PkgPath: "example.com/target", Fset: token.NewFileSet()}
+	f, err := parser.ParseFile(pkg.Fset, filename, src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg.Syntax = []*ast.File{f}
+	pkg.TypesInfo = typeCheck(t, pkg.Fset, f)
+	// rewrite leaves the file as is, as rewriters do for up-to-date files.
+	rewrite := func(pkg *packages.Package, f *ast.File, plan FilePlan, diags *Diagnostics) ([]byte, error) {
+		return []byte(src), nil
+	}
+
+	for i, expected := range []string{"cache: 0 hits, 1 misses", "cache: 1 hits, 0 misses"} {
+		var stdout, stderr bytes.Buffer
+		code := Process(Config{Name: "test", Write: true, Stdout: &stdout, Stderr: &stderr}, []*packages.Package{pkg}, rewrite)
+		if code != 0 {
+			t.Fatalf("run %d: unexpected exit status %d: %s", i, code, stderr.String())
+		}
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("run %d: expected %q but got\n%s", i, expected, stdout.String())
+		}
+		if !strings.Contains(stderr.String(), "target.go:11:1: warning: enum directives are not attached to a type declaration") {
+			t.Errorf("run %d: expected the warning reported but got\n%s", i, stderr.String())
+		}
+	}
+}
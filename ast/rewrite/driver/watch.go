@@ -0,0 +1,81 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ngicks/go-example-code-generation/internal/watch"
+	"golang.org/x/tools/go/packages"
+)
+
+// watchLoop polls files and directories of pkgs, and reloads and rewrites packages whose files change
+// until interrupted.
+func watchLoop(cfg Config, pkgs []*packages.Package, rewrite RewriteFunc) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	stderr := cfg.stderr()
+
+	// inputs maps directories of packages to paths to watch: the directory, to notice added or removed files,
+	// and its Go files.
+	inputs := make(map[string][]string)
+	record := func(pkgs []*packages.Package) {
+		for _, pkg := range pkgs {
+			if len(pkg.GoFiles) == 0 {
+				continue
+			}
+			dir := filepath.Dir(pkg.GoFiles[0])
+			inputs[dir] = append([]string{dir}, pkg.GoFiles...)
+		}
+	}
+	record(pkgs)
+	fmt.Fprintf(stderr, "%s: watching %d packages\n", cfg.Name, len(inputs))
+
+	err := watch.Poll(
+		ctx,
+		cfg.Poll,
+		func() []string {
+			var paths []string
+			for _, p := range inputs {
+				paths = append(paths, p...)
+			}
+			return paths
+		},
+		func(changed []string) {
+			var dirs []string
+			for dir, paths := range inputs {
+				for _, c := range changed {
+					if slices.Contains(paths, c) {
+						dirs = append(dirs, dir)
+						break
+					}
+				}
+			}
+			slices.Sort(dirs)
+			fmt.Fprintf(stderr, "%s: changed %s, regenerating %s\n", cfg.Name, strings.Join(changed, ", "), strings.Join(dirs, ", "))
+
+			affected := cfg
+			affected.Patterns = dirs
+			pkgs, err := Load(affected)
+			if err != nil {
+				fmt.Fprintf(stderr, "%s: %v\n", cfg.Name, err)
+				return
+			}
+			for _, dir := range dirs {
+				delete(inputs, dir)
+			}
+			record(pkgs)
+			Process(affected, pkgs, rewrite)
+		},
+	)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintf(stderr, "%s: %v\n", cfg.Name, err)
+		return 1
+	}
+	return 0
+}
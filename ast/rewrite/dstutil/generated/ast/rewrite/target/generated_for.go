@@ -0,0 +1,8 @@
+package target
+
+//enum:generated_for=Color
+const (
+	ColorRed   Color = "red"
+	ColorGreen Color = "green"
+	ColorBlue  Color = "blue"
+)
@@ -0,0 +1,113 @@
+package target
+
+//enum:variants=foo,bar,baz
+type Enum string
+
+//enum:generated_for=Enum
+const (
+	EnumFoo Enum = "foo"
+	EnumBar Enum = "bar"
+	EnumBaz Enum = "baz"
+)
+
+//enum:variants=foo,bar,baz
+type Enum2 string
+
+//enum:generated_for=Enum2
+const (
+	Enum2Foo Enum2 = "foo"
+	Enum2Bar Enum2 = "bar"
+	Enum2Baz Enum2 = "baz"
+)
+
+// Color is declared here, but its generated block lives in generated_for.go.
+//
+//enum:variants=red,green,blue
+type Color string
+
+//enum:variant active "StatusActive is the initial state."
+//enum:variant "on hold"
+//enum:variant "a,b" "StatusA_b contains a comma,\nwhich needs quoting."
+//enum:except=NotActive active
+type Status string
+
+//enum:generated_for=Status
+const (
+	// StatusActive is the initial state.
+	StatusActive  Status = "active"
+	StatusOn_hold Status = "on hold"
+	// StatusA_b contains a comma,
+	// which needs quoting.
+	StatusA_b Status = "a,b"
+)
+
+//enum:generated_for=Status
+func IsStatusExceptNotActive(v Status) bool {
+	return v != StatusActive
+}
+
+type (
+	// Size is declared in a group.
+	//
+	//enum:variants=small,large
+	Size   string
+	Weight int
+	//enum:variants=light,heavy
+	Mass string
+)
+
+//enum:generated_for=Size
+const (
+	SizeSmall Size = "small"
+	SizeLarge Size = "large"
+)
+
+//enum:generated_for=Mass
+const (
+	MassLight Mass = "light"
+	MassHeavy Mass = "heavy"
+)
+
+// label is not an enum itself, but its underlying type makes types defined on it string enums.
+type label string
+
+//enum:variants=low,high
+type Priority label
+
+//enum:generated_for=Priority
+const (
+	PriorityLow  Priority = "low"
+	PriorityHigh Priority = "high"
+)
+
+// Single has a single variant, whose block is still parenthesized.
+//
+//enum:variants=only
+type Single string
+
+//enum:generated_for=Single
+const (
+	SingleOnly Single = "only"
+)
+
+// Person has getters generated by the getter: handler of the dstutil rewriter.
+//
+//getter:fields=name,age
+type Person struct {
+	name string
+	age  int
+}
+
+// Name returns name of Person.
+//
+//getter:generated_for=Person
+func (p Person) Name() string {
+	return p.name
+}
+
+// Age returns age of Person.
+//
+//getter:generated_for=Person
+func (p Person) Age() int {
+	return p.age
+}
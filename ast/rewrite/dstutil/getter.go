@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dave/dst"
+	"github.com/ngicks/go-example-code-generation/ast/rewrite/driver"
+	"github.com/ngicks/go-example-code-generation/ast/rewrite/handler"
+	"golang.org/x/tools/go/packages"
+)
+
+// getter is an example handler generating getter methods for unexported fields of structs.
+//
+//	//getter:fields=name,age
+//	type Person struct {
+//		name string
+//		age  int
+//	}
+//
+// generates methods Name and Age returning the fields.
+type getter struct{}
+
+var _ handler.Handler = getter{}
+
+func (getter) Prefix() string {
+	return "getter:"
+}
+
+// Find returns structs annotated by getter directives in f, with names of fields to generate getters for.
+// Unknown directives, types other than structs and fields which do not exist or are exported are reported to diags.
+func (getter) Find(pkg *packages.Package, f *ast.File, diags *driver.Diagnostics) []driver.Annotation {
+	var found []driver.Annotation
+	for _, d := range driver.FindDirectives(f, "getter:") {
+		a := driver.Annotation{Spec: d.Spec}
+		names, ok := getterFields(pkg, d, diags)
+		if ok {
+			a.Value = names
+		}
+		found = append(found, a)
+	}
+	return found
+}
+
+func getterFields(pkg *packages.Package, d driver.TypeDirectives, diags *driver.Diagnostics) ([]string, bool) {
+	var names []string
+	for _, c := range d.Comments {
+		value, ok := strings.CutPrefix(c.Text, "//getter:fields=")
+		if !ok {
+			diags.Errorf(pkg.Fset.Position(c.Pos()), "unknown directive %q", c.Text)
+			return nil, false
+		}
+		for _, name := range strings.Split(value, ",") {
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+
+	fail := func(format string, args ...any) ([]string, bool) {
+		diags.Errorf(pkg.Fset.Position(d.Spec.Name.Pos()), "getter directives on type %s: %s", d.Spec.Name.Name, fmt.Sprintf(format, args...))
+		return nil, false
+	}
+	obj, _ := pkg.TypesInfo.Defs[d.Spec.Name].(*types.TypeName)
+	if obj == nil {
+		return fail("type information is not available")
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok || named.TypeParams().Len() > 0 {
+		return fail("it must be a defined type without type parameters")
+	}
+	structType, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return fail("it must be a struct but is %s", named.Underlying())
+	}
+	if _, ok := d.Spec.Type.(*ast.StructType); !ok {
+		return fail("it must be declared by a struct type literal")
+	}
+	for _, name := range names {
+		if !hasField(structType, name) {
+			return fail("it has no field %q", name)
+		}
+		if token.IsExported(name) {
+			return fail("field %q is exported", name)
+		}
+	}
+	return names, true
+}
+
+func hasField(structType *types.Struct, name string) bool {
+	for i := range structType.NumFields() {
+		if structType.Field(i).Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Generate returns getter methods for fields found by Find.
+func (getter) Generate(ctx *handler.Context) ([]dst.Decl, error) {
+	names, ok := ctx.Value.([]string)
+	if !ok {
+		return nil, fmt.Errorf("expected field names but got %T", ctx.Value)
+	}
+	structType, ok := ctx.Spec.Type.(*dst.StructType)
+	if !ok {
+		return nil, fmt.Errorf("it must be declared by a struct type literal")
+	}
+
+	typeName := ctx.Spec.Name.Name
+	recv := receiver(typeName)
+	var decls []dst.Decl
+	for _, name := range names {
+		field := findField(structType, name)
+		if field == nil {
+			return nil, fmt.Errorf("it has no field %q", name)
+		}
+		method := exported(name)
+		decls = append(decls, &dst.FuncDecl{
+			Recv: &dst.FieldList{
+				List: []*dst.Field{{Names: []*dst.Ident{dst.NewIdent(recv)}, Type: dst.NewIdent(typeName)}},
+			},
+			Name: dst.NewIdent(method),
+			Type: &dst.FuncType{
+				Results: &dst.FieldList{List: []*dst.Field{{Type: dst.Clone(field.Type).(dst.Expr)}}},
+			},
+			Body: &dst.BlockStmt{
+				List: []dst.Stmt{
+					&dst.ReturnStmt{
+						Results: []dst.Expr{&dst.SelectorExpr{X: dst.NewIdent(recv), Sel: dst.NewIdent(name)}},
+						Decs:    dst.ReturnStmtDecorations{NodeDecs: dst.NodeDecs{Before: dst.NewLine, After: dst.NewLine}},
+					},
+				},
+			},
+			Decs: dst.FuncDeclDecorations{
+				NodeDecs: dst.NodeDecs{
+					Start: dst.Decorations{fmt.Sprintf("// %s returns %s of %s.", method, name, typeName)},
+				},
+			},
+		})
+	}
+	return decls, nil
+}
+
+func findField(structType *dst.StructType, name string) *dst.Field {
+	for _, field := range structType.Fields.List {
+		for _, ident := range field.Names {
+			if ident.Name == name {
+				return field
+			}
+		}
+	}
+	return nil
+}
+
+// receiver returns the receiver name of methods of typeName, its first letter in lower case,
+// or v if that is not an identifier on its own, e.g. for _x.
+func receiver(typeName string) string {
+	r, _ := utf8.DecodeRuneInString(typeName)
+	recv := string(unicode.ToLower(r))
+	if !token.IsIdentifier(recv) || recv == "_" {
+		return "v"
+	}
+	return recv
+}
+
+func exported(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[size:]
+}
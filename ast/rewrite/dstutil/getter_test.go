@@ -0,0 +1,18 @@
+package main
+
+import "testing"
+
+func TestReceiver(t *testing.T) {
+	for _, tc := range []struct {
+		typeName, want string
+	}{
+		{"Person", "p"},
+		{"Élan", "é"},
+		{"_x", "v"},
+		{"x", "x"},
+	} {
+		if got := receiver(tc.typeName); got != tc.want {
+			t.Errorf("%s: expected %q but got %q", tc.typeName, tc.want, got)
+		}
+	}
+}
@@ -1,228 +1,134 @@
 package main
 
 import (
+	"bytes"
 	"errors"
-	"go/printer"
+	"flag"
+	"go/ast"
+	"go/format"
 	"go/token"
-	"io/fs"
 	"os"
 	"path/filepath"
 	"slices"
-	"strconv"
-	"strings"
 
 	"github.com/dave/dst"
 	"github.com/dave/dst/decorator"
-	"github.com/dave/dst/dstutil"
+	"github.com/ngicks/go-example-code-generation/ast/rewrite/driver"
+	"github.com/ngicks/go-example-code-generation/ast/rewrite/handler"
 	"golang.org/x/tools/go/packages"
 )
 
+// registry holds handlers generating declarations, including enums, planned by the driver.
+var registry handler.Registry
+
 func main() {
-	cfg := &packages.Config{
-		Mode: packages.NeedName |
-			packages.NeedImports |
-			packages.NeedTypes |
-			packages.NeedSyntax,
-	}
-	pkgs, err := packages.Load(cfg, "./ast/rewrite/target")
+	cfg, err := driver.ParseFlags(
+		"dstutil",
+		os.Args[1:],
+		filepath.Join("ast", "rewrite", "dstutil", "generated"),
+		"./ast/rewrite/target",
+	)
 	if err != nil {
-		panic(err)
-	}
-
-	generatedDir := filepath.Join("ast", "rewrite", "dstutil", "generated")
-	err = os.Mkdir(generatedDir, fs.ModePerm)
-	if err != nil && !errors.Is(err, fs.ErrExist) {
-		panic(err)
-	}
-
-	pkg := pkgs[0]
-
-	for _, f := range pkg.Syntax {
-		df, err := decorator.DecorateFile(pkg.Fset, f)
-		if err != nil {
-			panic(err)
-		}
-		dstutil.Apply(
-			df,
-			func(c *dstutil.Cursor) bool {
-				n := c.Node()
-				switch x := n.(type) {
-				default:
-					return true
-				case *dst.FuncDecl:
-				case *dst.GenDecl:
-					if x.Tok != token.TYPE {
-						break
-					}
-
-					if len(x.Specs) == 1 {
-						name, ok := isStringBasedType(x.Specs[0])
-						if !ok {
-							break
-						}
-						param, ok := parseDirective(x.Decs)
-						if !ok {
-							break
-						}
-						param.Name = name
-						addOrReplaceEnum(c, param)
-					}
-				}
-				return false
-			},
-			nil,
-		)
-
-		out, err := os.Create(filepath.Join(generatedDir, filepath.Base(pkg.Fset.Position(f.FileStart).Filename)))
-		if err != nil {
-			panic(err)
+		if errors.Is(err, flag.ErrHelp) {
+			return
 		}
-
-		restorer := decorator.NewRestorer()
-		af, err := restorer.RestoreFile(df)
+		os.Exit(2)
+	}
+	for _, h := range []handler.Handler{handler.Enum(cfg.Integers), getter{}} {
+		err := registry.Register(h)
 		if err != nil {
 			panic(err)
 		}
-		printer.Fprint(out, restorer.Fset, af)
 	}
-}
+	cfg.Generators = registry.Generators()
 
-func isStringBasedType(spec dst.Spec) (string, bool) {
-	typ, ok := spec.(*dst.TypeSpec)
-	if !ok {
-		return "", false
-	}
-	id, ok := typ.Type.(*dst.Ident)
-	if !ok {
-		return "", false
-	}
-	return typ.Name.Name, id.Name == "string"
-}
-
-type EnumParam struct {
-	Name     string
-	Variants []string
-}
-
-func parseDirective(decorations dst.GenDeclDecorations) (EnumParam, bool) {
-	for i := len(decorations.Start) - 1; i >= 0; i-- {
-		line := decorations.Start[i]
-		if len(strings.TrimSpace(line)) == 0 {
-			// start of comments groups that is not associated to gen decl.
-			break
-		}
-		c := stripMarker(line)
-		c, isDirection := strings.CutPrefix(c, "enum:variants=")
-		if !isDirection {
-			continue
-		}
-		return EnumParam{Variants: strings.Split(c, ",")}, true
-	}
-	return EnumParam{}, false
+	os.Exit(driver.Run(cfg, rewrite))
 }
 
-func stripMarker(text string) string {
-	if len(text) < 2 {
-		return text
+func rewrite(pkg *packages.Package, f *ast.File, plan driver.FilePlan, diags *driver.Diagnostics) ([]byte, error) {
+	dec := decorator.NewDecorator(pkg.Fset)
+	df, err := dec.DecorateFile(f)
+	if err != nil {
+		return nil, err
 	}
-	switch text[1] {
-	case '/':
-		return text[2:]
-	case '*':
-		return text[2 : len(text)-2]
+	// generate returns declarations generated for gen, or reports the error of its handler at the type and returns false.
+	generate := func(gen driver.Generation) ([]dst.Decl, bool) {
+		decls, err := registry.Generate(pkg, gen)
+		if err != nil {
+			diags.Errorf(pkg.Fset.Position(gen.Spec.Pos()), "%s%s: %v", gen.Prefix, gen.Spec.Name.Name, err)
+			return nil, false
+		}
+		return decls, true
 	}
-	return text
-}
 
-func addOrReplaceEnum(c *dstutil.Cursor, param EnumParam) {
-	found := false
-	dstutil.Apply(
-		c.Parent(),
-		func(c *dstutil.Cursor) bool {
-			node := c.Node()
-			switch x := node.(type) {
-			default:
-				return true
-			case *dst.FuncDecl:
-			case *dst.GenDecl:
-				if x.Tok != token.CONST {
-					break
-				}
-				if !isGeneratedFor(x.Decs, param.Name) {
-					break
+	var (
+		decls []dst.Decl
+		// carried holds comments around a removed declaration other than its doc comments,
+		// to be moved onto the next declaration.
+		carried dst.Decorations
+	)
+	for _, decl := range df.Decls {
+		orig, _ := dec.Ast.Nodes[decl].(ast.Decl)
+		decs := decl.Decorations()
+		nonDoc := decs.Start[:len(decs.Start)-len(handler.DocComments(decs.Start))]
+		removed := slices.Contains(plan.Remove, orig)
+		if gen, ok := plan.Replace[orig]; ok {
+			// decl is kept as is if it fails to be regenerated.
+			newDecls, ok := generate(gen)
+			if ok && len(newDecls) > 0 {
+				// The doc comments of decl are replaced by ones of newDecls,
+				// unless the handler sets none and they end with the marker, e.g. when edited by hand.
+				first, last := newDecls[0].Decorations(), newDecls[len(newDecls)-1].Decorations()
+				doc := first.Start
+				if len(doc) == 1 && len(decs.Start) > 0 && decs.Start[len(decs.Start)-1] == doc[0] {
+					doc = handler.DocComments(decs.Start)
 				}
-				found = true
-				c.Replace(astVariants(param, x.Decs))
+				first.Before = decs.Before
+				first.Start = slices.Concat(carried, nonDoc, doc)
+				last.End = decs.End
+				carried = nil
+				decls = append(decls, newDecls...)
+				continue
 			}
-			return false
-		},
-		nil,
-	)
-	if !found {
-		c.InsertAfter(astVariants(param, dst.GenDeclDecorations{}))
-	}
-}
-
-func isGeneratedFor(decorations dst.GenDeclDecorations, fotTy string) bool {
-	for i := len(decorations.Start) - 1; i >= 0; i-- {
-		line := decorations.Start[i]
-		if len(strings.TrimSpace(line)) == 0 {
-			break
+			removed = ok
 		}
-		c := stripMarker(line)
-		s, ok := strings.CutPrefix(c, "enum:generated_for=")
-		if !ok {
-			return false
-		}
-		if s == fotTy {
-			return true
+		if removed {
+			carried = append(carried, nonDoc...)
+			carried = append(carried, decs.End...)
+			continue
 		}
-	}
-	return false
-}
-
-func astVariants(param EnumParam, targetDecoration dst.GenDeclDecorations) *dst.GenDecl {
-	if len(targetDecoration.Start) > 0 && targetDecoration.Start[len(targetDecoration.Start)-1] != "//enum:generated_for="+param.Name {
-		var i int
-		for i = len(targetDecoration.Start) - 1; i >= 0; i-- {
-			if targetDecoration.Start[i] == "\n" {
-				break
+		decs.Start = append(carried, decs.Start...)
+		carried = nil
+		decls = append(decls, decl)
+		if gen, ok := orig.(*ast.GenDecl); ok && gen.Tok == token.TYPE {
+			for _, spec := range gen.Specs {
+				for _, g := range plan.InsertAfter[spec.(*ast.TypeSpec)] {
+					newDecls, _ := generate(g)
+					decls = append(decls, newDecls...)
+				}
 			}
 		}
-		if i < 0 {
-			i = len(targetDecoration.Start) - 1
-		}
-		targetDecoration.Start = append(slices.Clone(targetDecoration.Start[:i]), "\n", "//enum:generated_for="+param.Name)
-	} else {
-		targetDecoration.Start = []string{"//enum:generated_for=" + param.Name}
 	}
-	return &dst.GenDecl{
-		Decs:   targetDecoration,
-		Tok:    token.CONST,
-		Lparen: true,
-		Specs:  mapParamToSpec(param),
-		Rparen: true,
+	for _, gen := range plan.Append {
+		newDecls, _ := generate(gen)
+		decls = append(decls, newDecls...)
 	}
-}
-
-func mapParamToSpec(param EnumParam) []dst.Spec {
-	specs := make([]dst.Spec, len(param.Variants))
-	for i, variant := range param.Variants {
-		specs[i] = &dst.ValueSpec{
-			Names:  []*dst.Ident{{Name: param.Name + capitalize(variant)}},
-			Type:   &dst.Ident{Name: param.Name},
-			Values: []dst.Expr{&dst.BasicLit{Kind: token.STRING, Value: strconv.Quote(variant)}},
-		}
+	df.Decls = decls
+	if len(carried) > 0 && carried[0] != "\n" {
+		// No declaration follows, so carried comments are separated from the one before, or the package clause.
+		carried = append(dst.Decorations{"\n"}, carried...)
 	}
-	return specs
-}
+	df.Decs.End = append(carried, df.Decs.End...)
 
-func capitalize(s string) string {
-	if len(s) == 0 {
-		return s
+	restorer := decorator.NewRestorer()
+	af, err := restorer.RestoreFile(df)
+	if err != nil {
+		return nil, err
 	}
-	if len(s) == 1 {
-		return strings.ToUpper(s)
+	var buf bytes.Buffer
+	err = format.Node(&buf, restorer.Fset, af)
+	if err != nil {
+		return nil, err
 	}
-	return strings.ToUpper(s[:1]) + s[1:]
+	return buf.Bytes(), nil
 }
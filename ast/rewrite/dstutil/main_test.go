@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ngicks/go-example-code-generation/ast/rewrite/driver"
+	"golang.org/x/tools/go/packages"
+)
+
+// writeSyntheticModule writes a module of files each declaring typesPerFile annotated enums.
+func writeSyntheticModule(b *testing.B, files, typesPerFile int) string {
+	dir := b.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module synthetic\n\ngo 1.22\n"), 0o644)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := range files {
+		var sb strings.Builder
+		sb.WriteString("package synthetic\n")
+		for j := range typesPerFile {
+			fmt.Fprintf(&sb, "\n//enum:variants=foo,bar,baz,qux,quux,corge,grault,garply,waldo,fred\ntype Enum%d_%d string\n", i, j)
+		}
+		err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d.go", i)), []byte(sb.String()), 0o644)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func BenchmarkRewrite(b *testing.B) {
+	dir := writeSyntheticModule(b, 100, 5)
+	wd, err := os.Getwd()
+	if err != nil {
+		b.Fatal(err)
+	}
+	err = os.Chdir(dir)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	b.Setenv("XDG_CACHE_HOME", b.TempDir())
+
+	cfg := driver.Config{
+		Name:     "dstutil",
+		Out:      "out",
+		Patterns: []string{"./..."},
+		Force:    true,
+		Stdout:   io.Discard,
+		Stderr:   io.Discard,
+	}
+	pkgs, err := driver.Load(cfg)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, jobs := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("j=%d", jobs), func(b *testing.B) {
+			cfg.Jobs = jobs
+			for range b.N {
+				if code := driver.Process(cfg, pkgs, rewrite); code != 0 {
+					b.Fatalf("exit status %d", code)
+				}
+			}
+		})
+	}
+}
+
+func TestRewriteRemoveLast(t *testing.T) {
+	for _, tc := range []struct {
+		name, src, expected string
+	}{
+		{
+			name:     "only declaration",
+			src:      "package p\n\n// keep me\n\n//enum:generated_for=A\nconst (\n\tAFoo A = \"foo\"\n)\n",
+			expected: "package p\n\n// keep me\n",
+		},
+		{
+			name:     "after a declaration",
+			src:      "package p\n\nfunc f() {}\n\n// keep me\n\n//enum:generated_for=A\nconst (\n\tAFoo A = \"foo\"\n)\n",
+			expected: "package p\n\nfunc f() {}\n\n// keep me\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pkg := &packages.Package{Name: "p", Fset: token.NewFileSet()}
+			f, err := parser.ParseFile(pkg.Fset, "b.go", tc.src, parser.ParseComments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var diags driver.Diagnostics
+			out, err := rewrite(pkg, f, driver.FilePlan{Remove: []ast.Decl{f.Decls[len(f.Decls)-1]}}, &diags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The same as the output of astutil.
+			if string(out) != tc.expected {
+				t.Errorf("expected\n%s\nbut got\n%s", tc.expected, out)
+			}
+		})
+	}
+}
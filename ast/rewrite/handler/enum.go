@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"fmt"
+	"go/token"
+
+	"github.com/dave/dst"
+	"github.com/ngicks/go-example-code-generation/ast/rewrite/driver"
+	"github.com/ngicks/go-example-code-generation/enum"
+)
+
+// Enum returns the handler of enums declared by //enum: directives,
+// generating a const block of variants for each, followed by predicates of its excepts.
+// If integers is set, enums whose underlying type is an integer are accepted.
+func Enum(integers bool) Handler {
+	return enumHandler{driver.EnumGenerator{Integers: integers}}
+}
+
+type enumHandler struct {
+	driver.EnumGenerator
+}
+
+func (enumHandler) Generate(ctx *Context) ([]dst.Decl, error) {
+	param, ok := ctx.Value.(enum.EnumParam)
+	if !ok {
+		return nil, fmt.Errorf("expected enum.EnumParam but got %T", ctx.Value)
+	}
+	block := &dst.GenDecl{
+		Tok:    token.CONST,
+		Lparen: true,
+		Specs:  enum.DstValueSpecs(param),
+		Rparen: true,
+	}
+	return append([]dst.Decl{block}, enum.DstExceptPredicates(param)...), nil
+}
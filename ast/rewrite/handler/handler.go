@@ -0,0 +1,147 @@
+// Package handler lets generators plug into the dst rewriter by the prefix of their directives.
+//
+// A handler registered for a prefix, e.g. "getter:", finds types whose doc comments have directives
+// starting with //getter:, and returns declarations generated for each of them.
+// Handlers are generators of the driver, so they are planned alike enums, which are generated by the handler Enum:
+// generated declarations are marked with
+//
+//	//getter:generated_for=Type
+//
+// inserted after the type declaration, or appended to the companion file, on the first run,
+// replaced wherever they are in the package on later runs,
+// and removed, or warned about, once the type no longer has directives of the prefix.
+package handler
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/ngicks/go-example-code-generation/ast/rewrite/driver"
+	"golang.org/x/tools/go/packages"
+)
+
+// Context is what a handler receives for an annotated type.
+type Context struct {
+	Pkg *packages.Package
+	// Info is type information of Pkg.
+	Info *types.Info
+	// Spec is the decorated copy of the annotated spec, which may be declared in a file other than the one rewritten.
+	// Handlers may reuse its nodes in declarations they return.
+	Spec *dst.TypeSpec
+	// Object is the type declared by Spec, or nil if Info has no definition for it.
+	Object *types.TypeName
+	// Value is the value of the annotation the handler found for the type.
+	Value any
+}
+
+// Handler generates declarations for types annotated by directives of its prefix.
+// Directives are validated by Find, whose annotations with nil values are not generated.
+type Handler interface {
+	driver.Generator
+	// Generate returns declarations generated for the type in ctx.
+	// Each of them is marked as generated for the type, after doc comments the handler may set on it.
+	Generate(ctx *Context) ([]dst.Decl, error)
+}
+
+// Registry holds handlers by their prefixes.
+// The zero value is an empty registry ready to use.
+type Registry struct {
+	handlers []Handler
+}
+
+// Register adds h to r.
+// It reports an error if the prefix of h is malformed or is already registered.
+func (r *Registry) Register(h Handler) error {
+	prefix := h.Prefix()
+	if !validPrefix(prefix) {
+		return fmt.Errorf("handler: prefix %q must be a word followed by ':', e.g. getter:", prefix)
+	}
+	if slices.ContainsFunc(r.handlers, func(registered Handler) bool { return registered.Prefix() == prefix }) {
+		return fmt.Errorf("handler: prefix %q is already registered", prefix)
+	}
+	r.handlers = append(r.handlers, h)
+	return nil
+}
+
+func validPrefix(prefix string) bool {
+	name, ok := strings.CutSuffix(prefix, ":")
+	if !ok || name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// Generators returns registered handlers in the order of registration, to be set to driver.Config.Generators.
+func (r *Registry) Generators() []driver.Generator {
+	generators := make([]driver.Generator, len(r.handlers))
+	for i, h := range r.handlers {
+		generators[i] = h
+	}
+	return generators
+}
+
+// Generate calls the handler of gen, planned for a file of pkg, and returns declarations it generates, marked for the type.
+func (r *Registry) Generate(pkg *packages.Package, gen driver.Generation) ([]dst.Decl, error) {
+	i := slices.IndexFunc(r.handlers, func(h Handler) bool { return h.Prefix() == gen.Prefix })
+	if i < 0 {
+		return nil, fmt.Errorf("handler: no handler is registered for prefix %q", gen.Prefix)
+	}
+	node, err := decorator.NewDecorator(pkg.Fset).DecorateNode(gen.Spec)
+	if err != nil {
+		return nil, err
+	}
+	ctx := &Context{
+		Pkg:    pkg,
+		Info:   pkg.TypesInfo,
+		Spec:   node.(*dst.TypeSpec),
+		Object: typeName(pkg.TypesInfo, gen.Spec),
+		Value:  gen.Value,
+	}
+	decls, err := r.handlers[i].Generate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, decl := range decls {
+		mark(decl, driver.Marker(gen.Prefix, gen.Spec.Name.Name))
+	}
+	return decls, nil
+}
+
+// DocComments returns comments directly above a declaration, excluding ones separated by an empty line.
+func DocComments(start dst.Decorations) []string {
+	i := len(start)
+	for i > 0 && strings.TrimSpace(start[i-1]) != "" {
+		i--
+	}
+	return start[i:]
+}
+
+// mark appends marker to doc comments of decl, separating them from the previous declaration by an empty line.
+// As gofmt does for directives, an empty comment line separates the marker from the doc text.
+func mark(decl dst.Decl, marker string) {
+	decs := decl.Decorations()
+	if len(decs.Start) > 0 {
+		decs.Start = append(decs.Start, "//")
+	}
+	decs.Start = append(decs.Start, marker)
+	decs.Before = dst.EmptyLine
+}
+
+func typeName(info *types.Info, spec *ast.TypeSpec) *types.TypeName {
+	if info == nil {
+		return nil
+	}
+	obj, _ := info.Defs[spec.Name].(*types.TypeName)
+	return obj
+}
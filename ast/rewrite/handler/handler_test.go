@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/ngicks/go-example-code-generation/ast/rewrite/driver"
+	"github.com/ngicks/go-example-code-generation/enum"
+	"golang.org/x/tools/go/packages"
+)
+
+// testHandler generates a var declaration for types having directives of its prefix.
+type testHandler struct {
+	prefix string
+}
+
+func (h testHandler) Prefix() string {
+	return h.prefix
+}
+
+func (h testHandler) Find(pkg *packages.Package, f *ast.File, diags *driver.Diagnostics) []driver.Annotation {
+	var found []driver.Annotation
+	for _, d := range driver.FindDirectives(f, h.prefix) {
+		found = append(found, driver.Annotation{Spec: d.Spec, Value: d.Comments[0].Text})
+	}
+	return found
+}
+
+func (h testHandler) Generate(ctx *Context) ([]dst.Decl, error) {
+	if ctx.Value == "//"+h.prefix+"fail" {
+		return nil, errors.New("failed")
+	}
+	return []dst.Decl{
+		&dst.GenDecl{
+			Tok: token.VAR,
+			Specs: []dst.Spec{
+				&dst.ValueSpec{
+					Names:  []*dst.Ident{dst.NewIdent(ctx.Spec.Name.Name + "Directive")},
+					Values: []dst.Expr{&dst.BasicLit{Kind: token.STRING, Value: "`" + ctx.Value.(string) + "`"}},
+				},
+			},
+			Decs: dst.GenDeclDecorations{
+				NodeDecs: dst.NodeDecs{Start: dst.Decorations{"// generated for " + ctx.Spec.Name.Name}},
+			},
+		},
+	}, nil
+}
+
+const src = `package target
+
+//test:foo
+type A string
+
+//test:fail
+type B string
+`
+
+// generate plans src as the only file of a package with generators of r, and generates declarations for each type.
+func generate(t *testing.T, r *Registry, src string) (map[string]string, map[string]error) {
+	t.Helper()
+	pkg := &packages.Package{Name: "target", Fset: token.NewFileSet()}
+	f, err := parser.ParseFile(pkg.Fset, "target.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var diags driver.Diagnostics
+	out, errs := make(map[string]string), make(map[string]error)
+	for _, g := range r.Generators() {
+		for _, a := range g.Find(pkg, f, &diags) {
+			decls, err := r.Generate(pkg, driver.Generation{Prefix: g.Prefix(), Spec: a.Spec, Value: a.Value})
+			if err != nil {
+				errs[a.Spec.Name.Name] = err
+				continue
+			}
+			out[a.Spec.Name.Name] = restore(t, decls)
+		}
+	}
+	if len(diags.List) > 0 {
+		t.Fatalf("unexpected diagnostics %v", diags.List)
+	}
+	return out, errs
+}
+
+func restore(t *testing.T, decls []dst.Decl) string {
+	t.Helper()
+	restorer := decorator.NewRestorer()
+	af, err := restorer.RestoreFile(&dst.File{Name: dst.NewIdent("target"), Decls: decls})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	err = format.Node(&buf, restorer.Fset, af)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestRegistryGenerate(t *testing.T) {
+	var r Registry
+	if err := r.Register(testHandler{"test:"}); err != nil {
+		t.Fatal(err)
+	}
+	out, errs := generate(t, &r, src)
+	expected := "package target\n\n// generated for A\n//\n//test:generated_for=A\nvar ADirective = `//test:foo`\n"
+	if out["A"] != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, out["A"])
+	}
+	if err := errs["B"]; err == nil || err.Error() != "failed" {
+		t.Errorf("expected an error of the handler but got %v", err)
+	}
+
+	spec := &ast.TypeSpec{Name: ast.NewIdent("C"), Type: ast.NewIdent("string")}
+	_, err := r.Generate(&packages.Package{Fset: token.NewFileSet()}, driver.Generation{Prefix: "other:", Spec: spec})
+	if err == nil || err.Error() != `handler: no handler is registered for prefix "other:"` {
+		t.Errorf("expected an error of the unknown prefix but got %v", err)
+	}
+}
+
+func TestEnum(t *testing.T) {
+	var r Registry
+	if err := r.Register(Enum(false)); err != nil {
+		t.Fatal(err)
+	}
+	spec := &ast.TypeSpec{Name: ast.NewIdent("Kind"), Type: ast.NewIdent("string")}
+	decls, err := r.Generate(
+		&packages.Package{Fset: token.NewFileSet()},
+		driver.Generation{Prefix: "enum:", Spec: spec, Value: enum.EnumParam{
+			Name:     "Kind",
+			Variants: []string{"foo", "bar", "baz"},
+			Excepts:  []enum.EnumExceptParam{{ExceptName: "Foo", ExcludedValiants: []string{"foo", "bar"}}},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `package target
+
+//enum:generated_for=Kind
+const (
+	KindFoo Kind = "foo"
+	KindBar Kind = "bar"
+	KindBaz Kind = "baz"
+)
+
+//enum:generated_for=Kind
+func IsKindExceptFoo(v Kind) bool {
+	return v != KindFoo && v != KindBar
+}
+`
+	if out := restore(t, decls); out != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, out)
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	var r Registry
+	for _, tc := range []struct {
+		prefix string
+		err    string
+	}{
+		{"getter:", ""},
+		{"getter:", `handler: prefix "getter:" is already registered`},
+		{"getter", `handler: prefix "getter" must be a word followed by ':', e.g. getter:`},
+		{"get ter:", `handler: prefix "get ter:" must be a word followed by ':', e.g. getter:`},
+	} {
+		err := r.Register(testHandler{tc.prefix})
+		if tc.err == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error %v", tc.prefix, err)
+			}
+		} else if err == nil || err.Error() != tc.err {
+			t.Errorf("%q: expected error %q but got %v", tc.prefix, tc.err, err)
+		}
+	}
+	if err := r.Register(Enum(false)); err != nil {
+		t.Errorf("unexpected error registering enums %v", err)
+	}
+	generators := r.Generators()
+	if len(generators) != 2 || generators[0].Prefix() != "getter:" || generators[1].Prefix() != "enum:" {
+		t.Errorf("expected getter: and enum: registered in order but got %v", generators)
+	}
+}
@@ -0,0 +1,407 @@
+// Package reflow edits declarations of a parsed go/ast file while keeping positions consistent,
+// so that go/printer places comments of both existing and synthesized nodes where they belong.
+//
+// Synthesized declarations are built without positions, comments included as Doc and Comment fields.
+// Const declarations are printed parenthesized even if they have a single spec.
+// When edits are applied, each one is rendered to source and parsed again into a gap opened in the file:
+// a new token.File, larger by the inserted text, replaces the original one,
+// and positions of existing nodes after each insertion point are shifted past it.
+package reflow
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+type editKind int
+
+const (
+	insertAfter editKind = iota
+	replace
+	remove
+	appendDecl
+)
+
+type edit struct {
+	kind editKind
+	old  ast.Node
+	new  ast.Decl
+}
+
+// Editor records edits to declarations of a file and applies them at once.
+type Editor struct {
+	fset  *token.FileSet
+	f     *ast.File
+	edits []edit
+}
+
+// NewEditor returns an editor of f, whose positions belong to fset.
+func NewEditor(fset *token.FileSet, f *ast.File) *Editor {
+	return &Editor{fset: fset, f: f}
+}
+
+// InsertAfter inserts decl after the line where anchor, a declaration of the file, ends.
+// Declarations inserted after the same anchor keep the order of calls.
+func (e *Editor) InsertAfter(anchor ast.Decl, decl ast.Decl) {
+	e.edits = append(e.edits, edit{kind: insertAfter, old: anchor, new: decl})
+}
+
+// Replace replaces old, a declaration of the file, with decl.
+// Comments of old, from its doc comment to its end, are dropped.
+func (e *Editor) Replace(old ast.Decl, decl ast.Decl) {
+	e.edits = append(e.edits, edit{kind: replace, old: old, new: decl})
+}
+
+// Delete deletes old, a declaration of the file, with its comments from its doc comment to its end.
+func (e *Editor) Delete(old ast.Decl) {
+	e.edits = append(e.edits, edit{kind: remove, old: old})
+}
+
+// Append appends decl to the end of the file.
+func (e *Editor) Append(decl ast.Decl) {
+	e.edits = append(e.edits, edit{kind: appendDecl, new: decl})
+}
+
+// insertion is text of a declaration inserted at offset of the original file.
+type insertion struct {
+	offset int
+	text   []byte
+	// decl and comments are parsed from text, positioned in the new file.
+	decl     ast.Decl
+	comments []*ast.CommentGroup
+}
+
+// Apply applies recorded edits to the file.
+// Declarations passed to the editor are rendered and parsed again,
+// so the file holds equivalent nodes with positions rather than the ones passed.
+//
+// The file is moved to a new token.File added to the file set, and positions of its nodes are rewritten,
+// so positions taken from the file before Apply must not be used afterwards.
+func (e *Editor) Apply() error {
+	tf := e.fset.File(e.f.FileStart)
+	if tf == nil {
+		return fmt.Errorf("reflow: file of %s is not in the file set", e.f.Name.Name)
+	}
+	size := tf.Size()
+	offset := func(pos token.Pos) int { return tf.Offset(pos) }
+	lineStartOf := func(pos token.Pos) int { return offset(tf.LineStart(tf.Line(pos))) }
+	lineStartAfter := func(pos token.Pos) int {
+		line := tf.Line(pos)
+		if line >= tf.LineCount() {
+			return size
+		}
+		return offset(tf.LineStart(line + 1))
+	}
+
+	var (
+		inserts []*insertion
+		removed = make(map[ast.Node]bool)
+		// dropped are ranges of the original file whose comments are dropped.
+		dropped [][2]int
+	)
+	for _, ed := range e.edits {
+		var at int
+		switch ed.kind {
+		case insertAfter:
+			at = lineStartAfter(ed.old.End())
+		case appendDecl:
+			at = size
+		case replace, remove:
+			start := ed.old.Pos()
+			if doc := declDoc(ed.old); doc != nil {
+				start = doc.Pos()
+			}
+			at = lineStartOf(start)
+			removed[ed.old] = true
+			dropped = append(dropped, [2]int{at, lineStartAfter(ed.old.End())})
+		}
+		if ed.new == nil {
+			continue
+		}
+		text, err := render(ed.new)
+		if err != nil {
+			return err
+		}
+		if at == size {
+			// The file may not end with a newline.
+			text = append([]byte{'\n'}, text...)
+		}
+		inserts = append(inserts, &insertion{offset: at, text: text})
+	}
+	// The sort is stable so that insertions at the same offset keep the order of edits.
+	slices.SortStableFunc(inserts, func(i, j *insertion) int { return i.offset - j.offset })
+
+	// shift maps an offset of the original file to the new one.
+	// Text inserted at an offset precedes the original text there.
+	shift := func(off int) int {
+		n := off
+		for _, ins := range inserts {
+			if ins.offset > off {
+				break
+			}
+			n += len(ins.text)
+		}
+		return n
+	}
+	newSize := shift(size)
+	nf := e.fset.AddFile(tf.Name(), -1, newSize)
+
+	lines := make(map[int]bool)
+	for _, start := range tf.Lines() {
+		lines[shift(start)] = true
+	}
+	inserted := 0
+	for _, ins := range inserts {
+		start := ins.offset + inserted
+		inserted += len(ins.text)
+		if slices.Contains(tf.Lines(), ins.offset) {
+			lines[start] = true
+		}
+		for i, b := range ins.text {
+			if b == '\n' {
+				lines[start+i+1] = true
+			}
+		}
+		err := ins.parse(nf, start)
+		if err != nil {
+			return err
+		}
+	}
+	sortedLines := make([]int, 0, len(lines))
+	for start := range lines {
+		if start < newSize {
+			sortedLines = append(sortedLines, start)
+		}
+	}
+	slices.Sort(sortedLines)
+	if !nf.SetLines(sortedLines) {
+		return fmt.Errorf("reflow: invalid lines of %s", tf.Name())
+	}
+
+	// Move nodes of the original file into the new one.
+	base := tf.Base()
+	move := func(pos token.Pos) token.Pos {
+		if !pos.IsValid() || int(pos) < base || int(pos) > base+size {
+			return pos
+		}
+		return token.Pos(nf.Base() + shift(int(pos)-base))
+	}
+	var (
+		decls    []ast.Decl
+		comments []*ast.CommentGroup
+		seen     = make(map[ast.Node]bool)
+	)
+	for _, decl := range e.f.Decls {
+		if !removed[decl] {
+			decls = append(decls, decl)
+		}
+	}
+	for _, cg := range e.f.Comments {
+		off := offset(cg.Pos())
+		if !slices.ContainsFunc(dropped, func(r [2]int) bool { return r[0] <= off && off < r[1] }) {
+			comments = append(comments, cg)
+		}
+		// Dropped groups may still be referenced as Doc of kept nodes, which are moved below.
+		setPos(cg, move, seen)
+	}
+	setPos(e.f, move, seen)
+
+	for _, ins := range inserts {
+		decls = append(decls, ins.decl)
+		comments = append(comments, ins.comments...)
+	}
+	slices.SortStableFunc(decls, func(i, j ast.Decl) int { return int(i.Pos()) - int(j.Pos()) })
+	slices.SortStableFunc(comments, func(i, j *ast.CommentGroup) int { return int(i.Pos()) - int(j.Pos()) })
+	e.f.Decls = decls
+	e.f.Comments = comments
+	e.edits = nil
+	return nil
+}
+
+// parse parses ins.text as a declaration placed at offset start of nf.
+func (ins *insertion) parse(nf *token.File, start int) error {
+	const header = "package p\n"
+	scratch := token.NewFileSet()
+	src := append([]byte(header), ins.text...)
+	f, err := parser.ParseFile(scratch, "", src, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("reflow: parsing rendered declaration: %w", err)
+	}
+	if len(f.Decls) != 1 {
+		return fmt.Errorf("reflow: rendered %d declarations, expected 1", len(f.Decls))
+	}
+	sf := scratch.File(f.FileStart)
+	move := func(pos token.Pos) token.Pos {
+		if !pos.IsValid() {
+			return pos
+		}
+		return token.Pos(nf.Base() + start + sf.Offset(pos) - len(header))
+	}
+	seen := make(map[ast.Node]bool)
+	for _, cg := range f.Comments {
+		setPos(cg, move, seen)
+	}
+	setPos(f.Decls[0], move, seen)
+	ins.decl = f.Decls[0]
+	ins.comments = f.Comments
+	return nil
+}
+
+var posType = reflect.TypeOf(token.NoPos)
+
+// setPos rewrites every position held by n and its descendants by move.
+// Nodes in seen are skipped, and visited nodes are added to it, so that no position is moved twice.
+func setPos(n ast.Node, move func(token.Pos) token.Pos, seen map[ast.Node]bool) {
+	ast.Inspect(n, func(n ast.Node) bool {
+		if n == nil || seen[n] {
+			return false
+		}
+		seen[n] = true
+		v := reflect.ValueOf(n).Elem()
+		for i := range v.NumField() {
+			field := v.Field(i)
+			if field.Type() == posType {
+				field.SetInt(int64(move(token.Pos(field.Int()))))
+			}
+		}
+		return true
+	})
+}
+
+// render prints decl, which has no positions, as source text surrounded by empty lines.
+// Const declarations are always parenthesized, so that a block keeps its shape as specs are added or removed.
+// go/printer cannot place comments without positions,
+// so decl is printed without comments first, and they are then added to lines of the nodes they belong to.
+func render(decl ast.Decl) ([]byte, error) {
+	type nodeComments struct {
+		doc, comment *ast.CommentGroup
+	}
+	var (
+		nodes    []ast.Node
+		comments = make(map[int]nodeComments)
+	)
+	ast.Inspect(decl, func(n ast.Node) bool {
+		switch n.(type) {
+		case nil, *ast.CommentGroup, *ast.Comment:
+			return false
+		}
+		if doc, comment := takeComments(n); doc != nil || comment != nil {
+			comments[len(nodes)] = nodeComments{doc, comment}
+		}
+		nodes = append(nodes, n)
+		return true
+	})
+
+	if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.CONST && !gen.Lparen.IsValid() {
+		// go/printer omits parentheses of a single spec unless Lparen is valid.
+		parenthesized := *gen
+		parenthesized.Lparen, parenthesized.Rparen = 1, 1
+		decl = &parenthesized
+	}
+
+	var buf bytes.Buffer
+	err := format.Node(&buf, token.NewFileSet(), decl)
+	if err != nil {
+		return nil, err
+	}
+	if len(comments) == 0 {
+		return wrap(buf.Bytes()), nil
+	}
+
+	// Find lines of nodes by parsing the printed declaration.
+	const header = "package p\n"
+	scratch := token.NewFileSet()
+	f, err := parser.ParseFile(scratch, "", header+buf.String(), 0)
+	if err != nil {
+		return nil, fmt.Errorf("reflow: parsing rendered declaration: %w", err)
+	}
+	var parsed []ast.Node
+	ast.Inspect(f.Decls[0], func(n ast.Node) bool {
+		if n != nil {
+			parsed = append(parsed, n)
+		}
+		return n != nil
+	})
+	if len(parsed) != len(nodes) {
+		return nil, fmt.Errorf("reflow: rendered declaration has %d nodes, expected %d", len(parsed), len(nodes))
+	}
+	lineOf := func(pos token.Pos) int { return scratch.Position(pos).Line - 2 }
+
+	srcLines := strings.SplitAfter(buf.String(), "\n")
+	before := make(map[int][]string)
+	after := make(map[int][]string)
+	for i, c := range comments {
+		n := parsed[i]
+		if c.doc != nil {
+			line := lineOf(n.Pos())
+			indent := srcLines[line][:len(srcLines[line])-len(strings.TrimLeft(srcLines[line], "\t"))]
+			for _, comment := range c.doc.List {
+				before[line] = append(before[line], indent+comment.Text+"\n")
+			}
+		}
+		if c.comment != nil {
+			line := lineOf(n.End())
+			for _, comment := range c.comment.List {
+				after[line] = append(after[line], " "+comment.Text)
+			}
+		}
+	}
+	var out bytes.Buffer
+	for i, line := range srcLines {
+		for _, b := range before[i] {
+			out.WriteString(b)
+		}
+		if len(after[i]) > 0 {
+			out.WriteString(strings.TrimSuffix(line, "\n"))
+			out.WriteString(strings.Join(after[i], ""))
+			out.WriteString("\n")
+			continue
+		}
+		out.WriteString(line)
+	}
+	return wrap(out.Bytes()), nil
+}
+
+// wrap surrounds text of a declaration with empty lines.
+func wrap(text []byte) []byte {
+	out := make([]byte, 0, len(text)+3)
+	out = append(out, '\n')
+	out = append(out, bytes.TrimSuffix(text, []byte("\n"))...)
+	return append(out, '\n', '\n')
+}
+
+// takeComments clears and returns the doc comment and the line comment of n.
+func takeComments(n ast.Node) (doc, comment *ast.CommentGroup) {
+	switch x := n.(type) {
+	case *ast.GenDecl:
+		doc, x.Doc = x.Doc, nil
+	case *ast.FuncDecl:
+		doc, x.Doc = x.Doc, nil
+	case *ast.ValueSpec:
+		doc, comment, x.Doc, x.Comment = x.Doc, x.Comment, nil, nil
+	case *ast.TypeSpec:
+		doc, comment, x.Doc, x.Comment = x.Doc, x.Comment, nil, nil
+	case *ast.ImportSpec:
+		doc, comment, x.Doc, x.Comment = x.Doc, x.Comment, nil, nil
+	case *ast.Field:
+		doc, comment, x.Doc, x.Comment = x.Doc, x.Comment, nil, nil
+	}
+	return doc, comment
+}
+
+func declDoc(n ast.Node) *ast.CommentGroup {
+	switch x := n.(type) {
+	case *ast.GenDecl:
+		return x.Doc
+	case *ast.FuncDecl:
+		return x.Doc
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package reflow
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+const src = `package target
+
+// A is documented.
+type A string // line comment of A
+
+// floating comment
+
+//test:generated
+const (
+	AOld A = "old" // dropped
+)
+
+func f() {
+	// inside f
+}
+
+// B is documented.
+type B string
+
+// doc of the removed block
+//removed
+const Removed = 1
+
+/* trailing comment */`
+
+const expected = `package target
+
+// A is documented.
+type A string // line comment of A
+
+// inserted after A
+const (
+	// AFoo is foo.
+	AFoo A = "foo"
+	ABar A = "bar" // bar
+)
+
+// floating comment
+
+//test:generated
+const (
+	ANew A = "new"
+)
+
+func f() {
+	// inside f
+}
+
+// B is documented.
+type B string
+
+/* trailing comment */
+
+//test:appended1
+var X = 1
+
+//test:appended2
+var Y = 2
+`
+
+func comments(texts ...string) *ast.CommentGroup {
+	if len(texts) == 0 {
+		return nil
+	}
+	cg := &ast.CommentGroup{}
+	for _, text := range texts {
+		cg.List = append(cg.List, &ast.Comment{Text: text})
+	}
+	return cg
+}
+
+func valueSpec(name, typ, value string, doc, comment *ast.CommentGroup) *ast.ValueSpec {
+	spec := &ast.ValueSpec{
+		Doc:     doc,
+		Names:   []*ast.Ident{{Name: name}},
+		Values:  []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: value}},
+		Comment: comment,
+	}
+	if typ != "" {
+		spec.Type = &ast.Ident{Name: typ}
+	}
+	return spec
+}
+
+func TestEditor(t *testing.T) {
+	fset := token.NewFileSet()
+	// Pad the file set so that positions of the file are far from the base, as in a loaded package.
+	fset.AddFile("padding.go", -1, 10000)
+	f, err := parser.ParseFile(fset, "target.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ed := NewEditor(fset, f)
+	ed.InsertAfter(f.Decls[0], &ast.GenDecl{
+		Doc: comments("// inserted after A"),
+		Tok: token.CONST,
+		Specs: []ast.Spec{
+			valueSpec("AFoo", "A", `"foo"`, comments("// AFoo is foo."), nil),
+			valueSpec("ABar", "A", `"bar"`, nil, comments("// bar")),
+		},
+	})
+	// A const declaration of a single spec is parenthesized, while var declarations appended below are not.
+	ed.Replace(f.Decls[1], &ast.GenDecl{
+		Doc:   comments("//test:generated"),
+		Tok:   token.CONST,
+		Specs: []ast.Spec{valueSpec("ANew", "A", `"new"`, nil, nil)},
+	})
+	ed.Delete(f.Decls[4])
+	ed.Append(&ast.GenDecl{Doc: comments("//test:appended1"), Tok: token.VAR, Specs: []ast.Spec{valueSpec("X", "", "1", nil, nil)}})
+	ed.Append(&ast.GenDecl{Doc: comments("//test:appended2"), Tok: token.VAR, Specs: []ast.Spec{valueSpec("Y", "", "2", nil, nil)}})
+	err = ed.Apply()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err = format.Node(&buf, fset, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, buf.String())
+	}
+
+	// Positions must be consistent: printing again after another round of edits gives the same result.
+	ed = NewEditor(fset, f)
+	err = ed.Apply()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	err = format.Node(&buf, fset, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected unchanged output after an empty round but got\n%s", buf.String())
+	}
+	for i := 1; i < len(f.Decls); i++ {
+		if f.Decls[i-1].End() >= f.Decls[i].Pos() {
+			t.Errorf("declarations %d and %d overlap", i-1, i)
+		}
+	}
+	if !strings.HasSuffix(fset.File(f.FileStart).Name(), "target.go") {
+		t.Errorf("file must keep its name but got %s", fset.File(f.FileStart).Name())
+	}
+}
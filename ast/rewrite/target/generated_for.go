@@ -0,0 +1,13 @@
+package target
+
+//enum:generated_for=Color
+const (
+	ColorRed Color = "red"
+)
+
+// Renamed no longer exists, so its block is removed.
+//
+//enum:generated_for=Renamed
+const (
+	RenamedFoo = "foo"
+)
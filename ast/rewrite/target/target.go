@@ -10,3 +10,43 @@ type Enum2 string
 const (
 	Enum2Foo = "foo"
 )
+
+// Color is declared here, but its generated block lives in generated_for.go.
+//
+//enum:variants=red,green,blue
+type Color string
+
+//enum:variant active "StatusActive is the initial state."
+//enum:variant "on hold"
+//enum:variant "a,b" "StatusA_b contains a comma,\nwhich needs quoting."
+//enum:except=NotActive active
+type Status string
+
+type (
+	// Size is declared in a group.
+	//
+	//enum:variants=small,large
+	Size   string
+	Weight int
+	//enum:variants=light,heavy
+	Mass string
+)
+
+// label is not an enum itself, but its underlying type makes types defined on it string enums.
+type label string
+
+//enum:variants=low,high
+type Priority label
+
+// Single has a single variant, whose block is still parenthesized.
+//
+//enum:variants=only
+type Single string
+
+// Person has getters generated by the getter: handler of the dstutil rewriter.
+//
+//getter:fields=name,age
+type Person struct {
+	name string
+	age  int
+}
@@ -0,0 +1,14 @@
+// enumexhaustive reports switch statements over enums generated by this project
+// which neither handle every variant nor have a default case.
+//
+//	go run ./cmd/enumexhaustive ./...
+package main
+
+import (
+	"github.com/ngicks/go-example-code-generation/enum/exhaustive"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(exhaustive.Analyzer)
+}
@@ -0,0 +1,369 @@
+// goenum generates an enum type into a file of the current directory.
+// It is meant to be run by go generate:
+//
+//	//go:generate go run github.com/ngicks/go-example-code-generation/cmd/goenum -type Status -variants active,inactive,deleted
+//	//go:generate go run github.com/ngicks/go-example-code-generation/cmd/goenum -spec enums.json -type Perm -output perm.go
+//
+// The package name is taken from $GOPACKAGE, set by go generate,
+// otherwise from the package clause of Go files in the directory of the output,
+// otherwise from the directory name.
+//
+// With -watch, goenum keeps running and generates again whenever the -spec file changes.
+//
+// With -check, goenum writes nothing but prints unified diffs of files differing from what it would generate,
+// so that CI can detect hand-edited or stale files.
+//
+// goenum exits with 2 on invalid flags and with 1 if generation fails or -check finds differences.
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ngicks/go-example-code-generation/enum"
+	"github.com/ngicks/go-example-code-generation/internal/diff"
+	"github.com/ngicks/go-example-code-generation/internal/watch"
+)
+
+const (
+	exitOk    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+type options struct {
+	typeName   string
+	variants   string
+	spec       string
+	output     string
+	backend    string
+	underlying string
+	start      int64
+	naming     string
+	marshal    bool
+	sql        bool
+	flags      bool
+	test       bool
+	check      bool
+	watch      bool
+	poll       time.Duration
+}
+
+// usageError is an error caused by invalid flags.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func usageErrorf(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	var opts options
+	fs := flag.NewFlagSet("goenum", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&opts.typeName, "type", "", "name of the enum type; required unless -spec defines exactly one enum")
+	fs.StringVar(&opts.variants, "variants", "", "comma separated variants, e.g. foo,bar,baz")
+	fs.StringVar(&opts.spec, "spec", "", "path to a .json or .csv spec file defining the enum instead of -variants and other flags")
+	fs.StringVar(&opts.output, "output", "", "output file name; default <type>_enum.go in lower case")
+	fs.StringVar(&opts.backend, "backend", "template", "code generation backend: "+backendNames())
+	fs.StringVar(&opts.underlying, "underlying", "", "underlying type, string or one of integer types; default string")
+	fs.Int64Var(&opts.start, "start", 0, "value of the first variant of an integer enum")
+	fs.StringVar(&opts.naming, "naming", "", "naming strategy of identifiers: "+namingNames())
+	fs.BoolVar(&opts.marshal, "marshal", false, "generate validating text and JSON marshalers")
+	fs.BoolVar(&opts.sql, "sql", false, "generate sql.Scanner and driver.Valuer")
+	fs.BoolVar(&opts.flags, "flags", false, "generate bit flags")
+	fs.BoolVar(&opts.test, "test", false, "also generate marshal round trip tests into <output>_test.go; requires marshaling")
+	fs.BoolVar(&opts.check, "check", false, "print unified diffs of files differing from generated code instead of writing them, and fail if any")
+	fs.BoolVar(&opts.watch, "watch", false, "keep running and generate again when the -spec file changes")
+	fs.DurationVar(&opts.poll, "poll", 500*time.Millisecond, "interval to poll the -spec file in watch mode")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: goenum [flags]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+
+	err := fs.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOk
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "goenum: unexpected arguments: %q\n", fs.Args())
+		fs.Usage()
+		return exitUsage
+	}
+
+	if opts.watch {
+		switch {
+		case opts.spec == "":
+			err = usageErrorf("-watch requires -spec")
+		case opts.check:
+			err = usageErrorf("-watch and -check are mutually exclusive")
+		}
+	}
+	if err == nil {
+		_, err = generate(opts, stdout)
+	}
+	if err != nil {
+		printError(stderr, err)
+		var usageErr *usageError
+		if errors.As(err, &usageErr) {
+			fs.Usage()
+			return exitUsage
+		}
+		if !opts.watch {
+			return exitError
+		}
+	}
+	if opts.watch {
+		return watchSpec(opts, stdout, stderr)
+	}
+	return exitOk
+}
+
+func printError(stderr io.Writer, err error) {
+	prefix := "goenum: "
+	if gofile := os.Getenv("GOFILE"); gofile != "" {
+		prefix += gofile + ": "
+	}
+	fmt.Fprintf(stderr, "%s%v\n", prefix, err)
+}
+
+// watchSpec generates the enum again each time the spec file changes, until interrupted.
+// Errors are logged without stopping.
+func watchSpec(opts options, stdout, stderr io.Writer) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	fmt.Fprintf(stderr, "goenum: watching %s\n", opts.spec)
+	err := watch.Poll(
+		ctx,
+		opts.poll,
+		func() []string { return []string{opts.spec} },
+		func([]string) {
+			written, err := generate(opts, stdout)
+			if err != nil {
+				printError(stderr, err)
+				return
+			}
+			fmt.Fprintf(stderr, "goenum: %s changed, regenerated %s\n", opts.spec, strings.Join(written, ", "))
+		},
+	)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		printError(stderr, err)
+		return exitError
+	}
+	return exitOk
+}
+
+// generate generates files of the enum and returns their paths.
+func generate(opts options, stdout io.Writer) ([]string, error) {
+	backend, ok := enum.LookupBackend(opts.backend)
+	if !ok {
+		return nil, usageErrorf("unknown backend %q: must be one of %s", opts.backend, backendNames())
+	}
+
+	param, err := loadParam(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.test && !param.Marshal {
+		return nil, usageErrorf("-test requires -marshal, or marshal enabled in the spec")
+	}
+
+	output := opts.output
+	if output == "" {
+		output = strings.ToLower(param.Name) + "_enum.go"
+	}
+
+	pkgName, err := packageName(param.PackageName, filepath.Dir(output), output)
+	if err != nil {
+		return nil, err
+	}
+	param.PackageName = pkgName
+
+	var buf bytes.Buffer
+	err = backend.Generate(&buf, param)
+	if err != nil {
+		return nil, err
+	}
+	out := &emitter{check: opts.check, w: stdout}
+	err = out.emit(output, buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.test {
+		buf.Reset()
+		err = enum.GenerateMarshalTest(&buf, param)
+		if err != nil {
+			return nil, err
+		}
+		err = out.emit(strings.TrimSuffix(output, ".go")+"_test.go", buf.Bytes())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(out.stale) > 0 {
+		return nil, fmt.Errorf("%s out of date: run go generate", strings.Join(out.stale, ", "))
+	}
+	return out.written, nil
+}
+
+// emitter writes generated files, or compares them to files on disk if check is set.
+type emitter struct {
+	check bool
+	w     io.Writer
+	// written lists files written.
+	written []string
+	// stale lists files differing from generated ones in check mode.
+	stale []string
+}
+
+func (e *emitter) emit(path string, src []byte) error {
+	if !e.check {
+		e.written = append(e.written, path)
+		return os.WriteFile(path, src, 0o644)
+	}
+	old, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	d := diff.Unified(path, path, old, src)
+	if d == nil {
+		return nil
+	}
+	e.stale = append(e.stale, path)
+	_, err = e.w.Write(d)
+	return err
+}
+
+// loadParam builds EnumParam from the spec file or from flags.
+func loadParam(opts options) (enum.EnumParam, error) {
+	if opts.spec == "" {
+		if opts.typeName == "" {
+			return enum.EnumParam{}, usageErrorf("-type is required")
+		}
+		if opts.variants == "" {
+			return enum.EnumParam{}, usageErrorf("-variants or -spec is required")
+		}
+		variants := strings.Split(opts.variants, ",")
+		for i, v := range variants {
+			variants[i] = strings.TrimSpace(v)
+		}
+		return enum.EnumParam{
+			Name:       opts.typeName,
+			Variants:   variants,
+			Underlying: opts.underlying,
+			Start:      opts.start,
+			Naming:     opts.naming,
+			Marshal:    opts.marshal,
+			SQL:        opts.sql,
+			Flags:      opts.flags,
+		}, nil
+	}
+
+	if opts.variants != "" {
+		return enum.EnumParam{}, usageErrorf("-variants and -spec are mutually exclusive")
+	}
+	params, err := enum.LoadSpecFile(opts.spec)
+	if err != nil {
+		return enum.EnumParam{}, err
+	}
+	if opts.typeName == "" {
+		if len(params) != 1 {
+			return enum.EnumParam{}, usageErrorf("-type is required since %s defines %d enums", opts.spec, len(params))
+		}
+		return params[0], nil
+	}
+	for _, p := range params {
+		if p.Name == opts.typeName {
+			return p, nil
+		}
+	}
+	return enum.EnumParam{}, fmt.Errorf("%s does not define enum %s", opts.spec, opts.typeName)
+}
+
+// packageName decides the package name of the generated file.
+// specPkg is the name given by the spec file, if any.
+// Go files in dir other than output and tests are examined when neither $GOPACKAGE nor specPkg is set.
+func packageName(specPkg, dir, output string) (string, error) {
+	if goPkg := os.Getenv("GOPACKAGE"); goPkg != "" {
+		if specPkg != "" && specPkg != goPkg {
+			return "", fmt.Errorf("spec defines package %q but $GOPACKAGE is %q", specPkg, goPkg)
+		}
+		return goPkg, nil
+	}
+	if specPkg != "" {
+		return specPkg, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", err
+	}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") || filepath.Clean(file) == filepath.Clean(output) {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.PackageClauseOnly)
+		if err != nil {
+			return "", err
+		}
+		return f.Name.Name, nil
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	name := strings.Map(func(r rune) rune {
+		if 'a' <= r && r <= 'z' || '0' <= r && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(filepath.Base(abs)))
+	if name == "" || '0' <= name[0] && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name, nil
+}
+
+func backendNames() string {
+	var names []string
+	for _, b := range enum.Backends() {
+		names = append(names, b.Name())
+	}
+	return strings.Join(names, "|")
+}
+
+func namingNames() string {
+	var names []string
+	for _, n := range enum.Namings() {
+		names = append(names, n.Name())
+	}
+	return strings.Join(names, "|")
+}
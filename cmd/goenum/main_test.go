@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	err := os.WriteFile(path, []byte(content), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Setenv("GOFILE", "")
+	t.Setenv("GOPACKAGE", "example")
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "enums.json")
+	writeFile(t, spec, `{"name": "Kind", "variants": ["a", "b"], "marshal": true}`)
+	stale := filepath.Join(dir, "stale.go")
+	writeFile(t, stale, "package example\n")
+
+	for _, tc := range []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+		// written lists files expected to be generated, relative to dir.
+		written []string
+	}{
+		{name: "variants", args: []string{"-type", "Status", "-variants", "active,inactive", "-output", "status.go"}, code: exitOk, written: []string{"status.go"}},
+		{name: "spec", args: []string{"-spec", spec, "-output", "kind.go", "-test"}, code: exitOk, written: []string{"kind.go", "kind_test.go"}},
+		{name: "help", args: []string{"-h"}, code: exitOk},
+		{name: "unknown flag", args: []string{"-nonexistent"}, code: exitUsage, stderr: "flag provided but not defined"},
+		{name: "arguments", args: []string{"-type", "Status", "-variants", "a", "extra"}, code: exitUsage, stderr: "unexpected arguments"},
+		{name: "no type", args: []string{"-variants", "a"}, code: exitUsage, stderr: "-type is required"},
+		{name: "no variants", args: []string{"-type", "Status"}, code: exitUsage, stderr: "-variants or -spec is required"},
+		{name: "variants and spec", args: []string{"-variants", "a", "-spec", spec}, code: exitUsage, stderr: "-variants and -spec are mutually exclusive"},
+		{name: "test without marshal", args: []string{"-type", "Status", "-variants", "a", "-output", "nomarshal.go", "-test"}, code: exitUsage, stderr: "-test requires -marshal"},
+		{name: "unknown backend", args: []string{"-type", "Status", "-variants", "a", "-backend", "nonexistent"}, code: exitUsage, stderr: `unknown backend "nonexistent"`},
+		{name: "watch without spec", args: []string{"-type", "Status", "-variants", "a", "-watch"}, code: exitUsage, stderr: "-watch requires -spec"},
+		{name: "invalid param", args: []string{"-type", "Status", "-variants", "a,a", "-output", "invalid.go"}, code: exitError, stderr: `duplicate variant "a"`},
+		{name: "unknown type in spec", args: []string{"-spec", spec, "-type", "Other"}, code: exitError, stderr: "does not define enum Other"},
+		{name: "check stale", args: []string{"-type", "Stale", "-variants", "a", "-output", "stale.go", "-check"}, code: exitError, stderr: "stale.go out of date"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			args := withOutputIn(dir, tc.args)
+			var stdout, stderr bytes.Buffer
+			code := run(args, &stdout, &stderr)
+			if code != tc.code {
+				t.Errorf("expected exit code %d but got %d, stderr:\n%s", tc.code, code, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tc.stderr) {
+				t.Errorf("expected stderr containing %q but got\n%s", tc.stderr, stderr.String())
+			}
+			for _, name := range tc.written {
+				src, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.HasPrefix(src, []byte("// Code generated")) || !bytes.Contains(src, []byte("package example\n")) {
+					t.Errorf("%s is not a generated file of package example:\n%s", name, src)
+				}
+			}
+		})
+	}
+
+	if src, err := os.ReadFile(stale); err != nil || string(src) != "package example\n" {
+		t.Errorf("-check must not write files but got %q, %v", src, err)
+	}
+	for _, name := range []string{"nomarshal.go", "invalid.go"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			t.Errorf("%s must not be written on errors", name)
+		}
+	}
+}
+
+// withOutputIn returns args with the value of -output joined to dir.
+func withOutputIn(dir string, args []string) []string {
+	args = append([]string(nil), args...)
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == "-output" {
+			args[i+1] = filepath.Join(dir, args[i+1])
+		}
+	}
+	return args
+}
+
+func TestPackageName(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		goPackage string
+		specPkg   string
+		// files are written into the directory of the output before packageName is called.
+		files map[string]string
+		// dir is the base name of the directory of the output.
+		dir  string
+		want string
+		err  string
+	}{
+		{name: "GOPACKAGE", goPackage: "fromenv", files: map[string]string{"a.go": "package fromfile\n"}, dir: "pkg", want: "fromenv"},
+		{name: "GOPACKAGE matches spec", goPackage: "example", specPkg: "example", dir: "pkg", want: "example"},
+		{name: "GOPACKAGE mismatches spec", goPackage: "fromenv", specPkg: "fromspec", dir: "pkg", err: `spec defines package "fromspec" but $GOPACKAGE is "fromenv"`},
+		{name: "spec", specPkg: "fromspec", files: map[string]string{"a.go": "package fromfile\n"}, dir: "pkg", want: "fromspec"},
+		{
+			name: "sibling files",
+			files: map[string]string{
+				"a_test.go":   "package fromtest_test\n",
+				"out_enum.go": "package stale\n",
+				"b.go":        "// Package fromfile is documented.\npackage fromfile\n",
+			},
+			dir:  "pkg",
+			want: "fromfile",
+		},
+		{name: "directory", dir: "pkg", want: "pkg"},
+		{name: "directory with leading digit", dir: "1pkg", want: "_1pkg"},
+		{name: "directory with invalid characters", dir: "My-Pkg.v2", want: "my_pkg_v2"},
+		{name: "malformed sibling", files: map[string]string{"a.go": "packag fromfile\n"}, dir: "pkg", err: "expected 'package'"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GOPACKAGE", tc.goPackage)
+			dir := filepath.Join(t.TempDir(), tc.dir)
+			err := os.Mkdir(dir, 0o755)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, content := range tc.files {
+				writeFile(t, filepath.Join(dir, name), content)
+			}
+			got, err := packageName(tc.specPkg, dir, filepath.Join(dir, "out_enum.go"))
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Errorf("expected error containing %q but got %q, %v", tc.err, got, err)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Errorf("expected %q but got %q, %v", tc.want, got, err)
+			}
+		})
+	}
+}
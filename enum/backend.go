@@ -0,0 +1,73 @@
+package enum
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"slices"
+)
+
+// Backend renders Go source code for an enum described by EnumParam.
+//
+// Every implementation writes gofmt'd code,
+// so the output for a same EnumParam is byte-identical regardless of the backend.
+type Backend interface {
+	Name() string
+	Generate(w io.Writer, param EnumParam) error
+}
+
+var backends = []Backend{
+	TemplateBackend{},
+	JenniferBackend{},
+	DstBackend{},
+}
+
+// Backends returns all backends implemented in this package.
+func Backends() []Backend {
+	return append([]Backend(nil), backends...)
+}
+
+// LookupBackend returns the backend whose Name is name.
+func LookupBackend(name string) (Backend, bool) {
+	for _, b := range backends {
+		if b.Name() == name {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// imports returns sorted paths of packages the generated code for p imports.
+func imports(p EnumParam) []string {
+	var paths []string
+	if !p.Flags {
+		paths = append(paths, "slices")
+	}
+	if p.IsInteger() {
+		paths = append(paths, "strconv")
+	}
+	if p.Flags {
+		paths = append(paths, "fmt", "strings")
+	}
+	if p.Marshal || p.SQL {
+		paths = append(paths, "fmt")
+	}
+	if p.Marshal {
+		paths = append(paths, "encoding/json")
+	}
+	if p.SQL {
+		paths = append(paths, "database/sql/driver")
+	}
+	slices.Sort(paths)
+	return slices.Compact(paths)
+}
+
+func writeFormatted(w io.Writer, src []byte) error {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return fmt.Errorf("formatting generated code: %w", err)
+	}
+	_, err = io.Copy(w, bytes.NewReader(formatted))
+	return err
+}
@@ -0,0 +1,215 @@
+package enum
+
+import (
+	"bytes"
+	"errors"
+	"go/format"
+	"reflect"
+	"testing"
+)
+
+var testParams = []EnumParam{
+	{
+		PackageName: "example",
+		Name:        "Perm",
+		Variants:    []string{"read", "write", "exec"},
+		Docs:        map[string]string{"exec": "PermExec allows execution."},
+		Underlying:  "uint8",
+		Marshal:     true,
+		SQL:         true,
+		Flags:       true,
+	},
+	{
+		PackageName: "example",
+		Name:        "Bits",
+		Variants:    []string{"b0", "b1", "b2", "b3", "b4", "b5", "b6", "b7"},
+		Underlying:  "uint8",
+		Flags:       true,
+	},
+	{
+		PackageName: "example",
+		Name:        "Status",
+		Variants:    []string{"active", "inactive", "deleted"},
+		Excepts: []EnumExceptParam{
+			{
+				ExceptName:       "deleted",
+				ExcludedValiants: []string{"deleted"},
+				Subset:           true,
+			},
+		},
+		Underlying: "uint8",
+		Start:      1,
+		Marshal:    true,
+		SQL:        true,
+	},
+	{
+		PackageName: "example",
+		Name:        "Level",
+		Variants:    []string{"debug", "info", "warn", "error"},
+		Docs:        map[string]string{"debug": "LevelDebug is the most verbose level.\n\nIt is disabled by default."},
+		Underlying:  "int",
+		Start:       -1,
+		SQL:         true,
+	},
+	{
+		PackageName: "example",
+		Name:        "Zero",
+		Variants:    []string{"zero", "one"},
+		Underlying:  "int64",
+	},
+	{
+		PackageName: "example",
+		Name:        "Enum",
+		Variants:    []string{"foo", "b\"ar", "baz"},
+		Docs:        map[string]string{"b\"ar": "EnumB_ar contains a quote."},
+		Excepts: []EnumExceptParam{
+			{
+				ExceptName:       "foo",
+				ExcludedValiants: []string{"foo"},
+			},
+			{
+				ExceptName:       "Muh",
+				ExcludedValiants: []string{"foo", "b\"ar"},
+				Subset:           true,
+			},
+		},
+	},
+	{
+		PackageName: "single",
+		Name:        "Single",
+		Variants:    []string{"only"},
+	},
+	{
+		PackageName: "unicode",
+		Name:        "Kind",
+		Variants:    []string{"a-b", "c d", "日本"},
+		Idents:      map[string]string{"a-b": "AToB"},
+		Excepts: []EnumExceptParam{
+			{
+				ExceptName:       "a-b",
+				ExcludedValiants: []string{"a-b", "日本"},
+			},
+		},
+		Marshal: true,
+		SQL:     true,
+	},
+	{
+		PackageName: "naming",
+		Name:        "Method",
+		Variants:    []string{"get", "http_post", "user-id", "HTTPServer", "élan", "1st"},
+		Excepts: []EnumExceptParam{
+			{
+				ExceptName:       "read-only",
+				ExcludedValiants: []string{"http_post", "user-id"},
+			},
+		},
+		Naming:  "pascal",
+		Marshal: true,
+	},
+}
+
+func TestBackendsGenerateSameCode(t *testing.T) {
+	for _, param := range testParams {
+		t.Run(param.Name, func(t *testing.T) {
+			var expected []byte
+			for _, b := range Backends() {
+				var buf bytes.Buffer
+				err := b.Generate(&buf, param)
+				if err != nil {
+					t.Fatalf("backend %s: %v", b.Name(), err)
+				}
+				formatted, err := format.Source(buf.Bytes())
+				if err != nil {
+					t.Fatalf("backend %s: generated code is not valid: %v", b.Name(), err)
+				}
+				if !bytes.Equal(buf.Bytes(), formatted) {
+					t.Errorf("backend %s: generated code is not gofmt'd", b.Name())
+				}
+				if expected == nil {
+					expected = formatted
+					continue
+				}
+				if !bytes.Equal(expected, formatted) {
+					t.Errorf(
+						"backend %s: output differs from backend %s.\nexpected:\n%s\nactual:\n%s",
+						b.Name(), Backends()[0].Name(), expected, formatted,
+					)
+				}
+			}
+		})
+	}
+}
+
+func TestLookupBackend(t *testing.T) {
+	for _, b := range Backends() {
+		found, ok := LookupBackend(b.Name())
+		if !ok || found.Name() != b.Name() {
+			t.Errorf("LookupBackend(%q) = %v, %t", b.Name(), found, ok)
+		}
+	}
+	if _, ok := LookupBackend("unknown"); ok {
+		t.Errorf("LookupBackend(%q) must not be found", "unknown")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		param EnumParam
+		err   bool
+	}{
+		{EnumParam{Name: "Enum", Variants: []string{"foo"}}, false},
+		{EnumParam{Variants: []string{"foo"}}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"foo"}, Underlying: "float64"}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"foo"}, Start: 1}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"foo", "bar"}, Underlying: "uint8", Start: 254}, false},
+		{EnumParam{Name: "Enum", Variants: []string{"foo", "bar"}, Underlying: "uint8", Start: 255}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"foo"}, Underlying: "uint16", Start: -1}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"foo", "bar"}, Underlying: "int8", Start: -128}, false},
+		{EnumParam{Name: "Enum", Variants: []string{"foo", "bar"}, Underlying: "int8", Start: -129}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"foo", "bar"}, Underlying: "uint64", Start: 1 << 62}, false},
+		{EnumParam{Name: "Enum", Variants: []string{"foo", "foo"}}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"foo"}, Excepts: []EnumExceptParam{{ExceptName: "foo", ExcludedValiants: []string{"foo"}, Subset: true}}}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"foo", "notBarFoo"}, Excepts: []EnumExceptParam{{ExceptName: "bar", Subset: true}}}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"foo"}, Naming: "snake"}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"foo_bar", "fooBar"}, Naming: "pascal"}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"b-ar", "b_ar"}}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"b-ar", "b_ar"}, Idents: map[string]string{"b-ar": "BDashAr"}}, false},
+		{EnumParam{Name: "Enum", Variants: []string{"b-ar", "b_ar"}, Idents: map[string]string{"b-ar": "B_ar"}}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"foo"}, Idents: map[string]string{"bar": "Bar"}}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"foo"}, Idents: map[string]string{"foo": "-"}}, true},
+		{EnumParam{Name: "Enum", Variants: []string{""}}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"foo"}, Excepts: []EnumExceptParam{{ExceptName: "bar", ExcludedValiants: []string{"bar"}}}}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"foo"}, Excepts: []EnumExceptParam{{ExcludedValiants: []string{"foo"}}}}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"foo"}, Underlying: "int", Flags: true}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"foo"}, Underlying: "uint8", Start: 1, Flags: true}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"foo|bar"}, Underlying: "uint8", Flags: true}, true},
+		{EnumParam{Name: "Enum", Variants: []string{"0", "1", "2", "3", "4", "5", "6", "7"}, Underlying: "uint8", Flags: true}, false},
+		{EnumParam{Name: "Enum", Variants: []string{"0", "1", "2", "3", "4", "5", "6", "7", "8"}, Underlying: "uint8", Flags: true}, true},
+	} {
+		err := tc.param.Validate()
+		if tc.err != (err != nil) {
+			t.Errorf("Validate(%#v) = %v, want error = %t", tc.param, err, tc.err)
+		}
+	}
+}
+
+func TestValidateReportsCollision(t *testing.T) {
+	err := EnumParam{Name: "Enum", Variants: []string{"b\"ar", "foo", "b-ar", "b_ar"}}.Validate()
+	var collision *IdentCollisionError
+	if !errors.As(err, &collision) {
+		t.Fatalf("expected *IdentCollisionError, got %T: %v", err, err)
+	}
+	want := map[string][]string{"EnumB_ar": {"b\"ar", "b-ar", "b_ar"}}
+	if !reflect.DeepEqual(collision.Collisions, want) {
+		t.Errorf("not equal:\nwant: %#v\ngot:  %#v", want, collision.Collisions)
+	}
+}
+
+func TestValidateRejectsEmptyVariant(t *testing.T) {
+	for _, underlying := range []string{"", "int", "uint8"} {
+		err := EnumParam{Name: "Enum", Variants: []string{"red", "", "green"}, Underlying: underlying}.Validate()
+		if err == nil || err.Error() != "enum Enum: empty variant" {
+			t.Errorf("underlying %q: expected empty variant error but got %v", underlying, err)
+		}
+	}
+}
@@ -0,0 +1,1132 @@
+package enum
+
+import (
+	"bytes"
+	"go/token"
+	"io"
+	"strconv"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+)
+
+// DstBackend generates enums by building a github.com/dave/dst tree and restoring it into Go source code.
+type DstBackend struct{}
+
+func (DstBackend) Name() string {
+	return "dst"
+}
+
+func (DstBackend) Generate(w io.Writer, param EnumParam) error {
+	err := param.Validate()
+	if err != nil {
+		return err
+	}
+
+	f := &dst.File{
+		Name: dst.NewIdent(param.PackageName),
+	}
+	f.Decs.Start = dst.Decorations{"// Code generated by me. DO NOT EDIT."}
+
+	// import "slices"
+	imports := imports(param)
+	importDecl := &dst.GenDecl{Tok: token.IMPORT, Lparen: len(imports) > 1}
+	for _, path := range imports {
+		importDecl.Specs = append(importDecl.Specs, &dst.ImportSpec{Path: &dst.BasicLit{Kind: token.STRING, Value: strconv.Quote(path)}})
+	}
+	f.Decls = append(f.Decls, importDecl)
+
+	// type Enum string
+	f.Decls = append(f.Decls, &dst.GenDecl{
+		Tok: token.TYPE,
+		Specs: []dst.Spec{&dst.TypeSpec{
+			Name: dst.NewIdent(param.Name),
+			Type: dst.NewIdent(param.UnderlyingType()),
+		}},
+	})
+
+	// const (
+	//     EnumFoo Enum = "foo"
+	// )
+	f.Decls = append(f.Decls, &dst.GenDecl{
+		Tok:    token.CONST,
+		Lparen: true,
+		Specs:  DstValueSpecs(param),
+		Rparen: true,
+	})
+
+	if param.Flags {
+		// const _PermMask Perm = PermFoo | PermBar
+		var mask dst.Expr = dstUintLit(0)
+		for i, variant := range param.Variants {
+			if i == 0 {
+				mask = dst.NewIdent(param.VariantIdent(variant))
+				continue
+			}
+			mask = &dst.BinaryExpr{X: mask, Op: token.OR, Y: dst.NewIdent(param.VariantIdent(variant))}
+		}
+		f.Decls = append(f.Decls, &dst.GenDecl{
+			Tok: token.CONST,
+			Specs: []dst.Spec{&dst.ValueSpec{
+				Names:  []*dst.Ident{dst.NewIdent("_" + param.Name + "Mask")},
+				Type:   dst.NewIdent(param.Name),
+				Values: []dst.Expr{mask},
+			}},
+		})
+	}
+
+	// var _EnumAll = [...]Enum{EnumFoo}
+	f.Decls = append(f.Decls, &dst.GenDecl{
+		Tok: token.VAR,
+		Specs: []dst.Spec{&dst.ValueSpec{
+			Names: []*dst.Ident{dst.NewIdent("_" + param.Name + "All")},
+			Values: []dst.Expr{&dst.CompositeLit{
+				Type: &dst.ArrayType{Len: &dst.Ellipsis{}, Elt: dst.NewIdent(param.Name)},
+				Elts: dstVariantIdents(param, param.Variants),
+			}},
+		}},
+	})
+
+	if param.Flags {
+		// func IsPerm(v Perm) bool { return v&^_PermMask == 0 }
+		isFlags := dstPredicate(
+			"Is"+param.Name,
+			param.Name,
+			&dst.BinaryExpr{
+				X:  &dst.BinaryExpr{X: dst.NewIdent("v"), Op: token.AND_NOT, Y: dst.NewIdent("_" + param.Name + "Mask")},
+				Op: token.EQL,
+				Y:  dstUintLit(0),
+			},
+		)
+		isFlags.Decs.Start = dst.Decorations{"// Is" + param.Name + " reports whether v consists only of flags of " + param.Name + "."}
+		f.Decls = append(f.Decls, isFlags)
+	} else {
+		// func IsEnum(v Enum) bool { return slices.Contains(_EnumAll[:], v) }
+		f.Decls = append(f.Decls, dstPredicate(
+			"Is"+param.Name,
+			param.Name,
+			&dst.CallExpr{
+				Fun: &dst.SelectorExpr{X: dst.NewIdent("slices"), Sel: dst.NewIdent("Contains")},
+				Args: []dst.Expr{
+					&dst.SliceExpr{X: dst.NewIdent("_" + param.Name + "All")},
+					dst.NewIdent("v"),
+				},
+			},
+		))
+	}
+
+	switch {
+	case param.Flags:
+		f.Decls = append(f.Decls, dstFlags(param)...)
+	case param.IsInteger():
+		f.Decls = append(f.Decls, dstStringer(param)...)
+	}
+
+	if param.Marshal || param.SQL {
+		f.Decls = append(f.Decls, dstInvalidError(param)...)
+	}
+
+	if param.Marshal {
+		f.Decls = append(f.Decls, dstMarshal(param)...)
+	}
+
+	if param.SQL {
+		f.Decls = append(f.Decls, dstSQL(param)...)
+	}
+
+	for _, except := range param.Excepts {
+		except = fillName(except, param.Name)
+		args := []dst.Expr{
+			&dst.CompositeLit{
+				Type: &dst.ArrayType{Elt: dst.NewIdent(except.Name)},
+				Elts: dstVariantIdents(param, except.ExcludedValiants),
+			},
+			dst.NewIdent("v"),
+		}
+		for _, arg := range args {
+			arg.Decorations().Before = dst.NewLine
+			arg.Decorations().After = dst.NewLine
+		}
+		// func IsEnumExceptFoo(v Enum) bool { return !slices.Contains([]Enum{EnumFoo}, v) }
+		f.Decls = append(f.Decls, dstPredicate(
+			param.ExceptIdent(except.ExceptName),
+			except.Name,
+			&dst.UnaryExpr{
+				Op: token.NOT,
+				X: &dst.CallExpr{
+					Fun:  &dst.SelectorExpr{X: dst.NewIdent("slices"), Sel: dst.NewIdent("Contains")},
+					Args: args,
+				},
+			},
+		))
+
+		if except.Subset {
+			f.Decls = append(f.Decls, dstSubset(param, except)...)
+		}
+	}
+
+	for _, decl := range f.Decls {
+		decl.Decorations().Before = dst.EmptyLine
+	}
+
+	var buf bytes.Buffer
+	err = decorator.NewRestorer().Fprint(&buf, f)
+	if err != nil {
+		return err
+	}
+	return writeFormatted(w, buf.Bytes())
+}
+
+// DstValueSpecs returns const specs for variants of the enum described by param.
+func DstValueSpecs(param EnumParam) []dst.Spec {
+	specs := make([]dst.Spec, len(param.Variants))
+	for i, variant := range param.Variants {
+		if param.IsInteger() {
+			spec := &dst.ValueSpec{Names: []*dst.Ident{dst.NewIdent(param.VariantIdent(variant))}}
+			if i == 0 {
+				spec.Type = dst.NewIdent(param.Name)
+				spec.Values = []dst.Expr{dstIotaExpr(param.Start)}
+				if param.Flags {
+					spec.Values = []dst.Expr{&dst.BinaryExpr{X: dstUintLit(1), Op: token.SHL, Y: dst.NewIdent("iota")}}
+				}
+			}
+			dstVariantDoc(param, variant, spec)
+			specs[i] = spec
+			continue
+		}
+		spec := &dst.ValueSpec{
+			Names:  []*dst.Ident{dst.NewIdent(param.VariantIdent(variant))},
+			Type:   dst.NewIdent(param.Name),
+			Values: []dst.Expr{&dst.BasicLit{Kind: token.STRING, Value: strconv.Quote(variant)}},
+		}
+		dstVariantDoc(param, variant, spec)
+		specs[i] = spec
+	}
+	return specs
+}
+
+// dstVariantDoc places the doc comment of variant above spec.
+func dstVariantDoc(param EnumParam, variant string, spec *dst.ValueSpec) {
+	doc := param.VariantDoc(variant)
+	if doc == nil {
+		return
+	}
+	spec.Decs.Before = dst.NewLine
+	spec.Decs.Start = doc
+}
+
+func dstVariantIdents(param EnumParam, variants []string) []dst.Expr {
+	elts := make([]dst.Expr, len(variants))
+	for i, variant := range variants {
+		elts[i] = dst.NewIdent(param.VariantIdent(variant))
+		elts[i].Decorations().Before = dst.NewLine
+		elts[i].Decorations().After = dst.NewLine
+	}
+	return elts
+}
+
+// DstExceptPredicates returns predicates generated for excepts of param, e.g. IsEnumExceptFoo.
+// Unlike ones of the dst backend, they compare v to excluded variants one by one,
+// so that the file they are inserted into needs no import.
+// Subset types of excepts are not included.
+func DstExceptPredicates(param EnumParam) []dst.Decl {
+	var decls []dst.Decl
+	for _, except := range param.Excepts {
+		// func IsEnumExceptFoo(v Enum) bool { return v != EnumFoo && v != EnumBar }
+		var result dst.Expr = dst.NewIdent("true")
+		for i, variant := range except.ExcludedValiants {
+			ne := &dst.BinaryExpr{X: dst.NewIdent("v"), Op: token.NEQ, Y: dst.NewIdent(param.VariantIdent(variant))}
+			if i == 0 {
+				result = ne
+				continue
+			}
+			result = &dst.BinaryExpr{X: result, Op: token.LAND, Y: ne}
+		}
+		decls = append(decls, dstPredicate(param.ExceptIdent(except.ExceptName), param.Name, result))
+	}
+	return decls
+}
+
+func dstPredicate(funcName, typeName string, result dst.Expr) *dst.FuncDecl {
+	ret := &dst.ReturnStmt{Results: []dst.Expr{result}}
+	ret.Decs.Before = dst.NewLine
+	ret.Decs.After = dst.NewLine
+	return &dst.FuncDecl{
+		Name: dst.NewIdent(funcName),
+		Type: &dst.FuncType{
+			Params: &dst.FieldList{List: []*dst.Field{{
+				Names: []*dst.Ident{dst.NewIdent("v")},
+				Type:  dst.NewIdent(typeName),
+			}}},
+			Results: &dst.FieldList{List: []*dst.Field{{Type: dst.NewIdent("bool")}}},
+		},
+		Body: &dst.BlockStmt{
+			List: []dst.Stmt{ret},
+		},
+	}
+}
+
+func dstSubset(param EnumParam, except EnumExceptParam) []dst.Decl {
+	subset := param.SubsetIdent(except.ExceptName)
+	variants := param.SubsetVariants(except)
+	allIdent := "_" + subset + "All"
+
+	// type EnumNotFoo Enum
+	typeDecl := &dst.GenDecl{
+		Tok: token.TYPE,
+		Specs: []dst.Spec{&dst.TypeSpec{
+			Name: dst.NewIdent(subset),
+			Type: dst.NewIdent(param.Name),
+		}},
+	}
+	typeDecl.Decs.Start = dst.Decorations{
+		"// " + subset + " is a subset of " + param.Name + " without variants excluded by except " + strconv.Quote(except.ExceptName) + ".",
+	}
+
+	// const (
+	//     EnumNotFooBar = EnumNotFoo(EnumBar)
+	// )
+	constDecl := &dst.GenDecl{Tok: token.CONST, Lparen: true, Rparen: true}
+	elts := make([]dst.Expr, len(variants))
+	for i, variant := range variants {
+		ident := param.SubsetVariantIdent(except.ExceptName, variant)
+		constDecl.Specs = append(constDecl.Specs, &dst.ValueSpec{
+			Names:  []*dst.Ident{dst.NewIdent(ident)},
+			Values: []dst.Expr{dstCall(dst.NewIdent(subset), dst.NewIdent(param.VariantIdent(variant)))},
+		})
+		elts[i] = dst.NewIdent(ident)
+		elts[i].Decorations().Before = dst.NewLine
+		elts[i].Decorations().After = dst.NewLine
+	}
+
+	// var _EnumNotFooAll = [...]EnumNotFoo{EnumNotFooBar}
+	allDecl := &dst.GenDecl{
+		Tok: token.VAR,
+		Specs: []dst.Spec{&dst.ValueSpec{
+			Names: []*dst.Ident{dst.NewIdent(allIdent)},
+			Values: []dst.Expr{&dst.CompositeLit{
+				Type: &dst.ArrayType{Len: &dst.Ellipsis{}, Elt: dst.NewIdent(subset)},
+				Elts: elts,
+			}},
+		}},
+	}
+
+	// func ToEnumNotFoo(v Enum) (EnumNotFoo, bool) {
+	//     if !slices.Contains(_EnumNotFooAll[:], EnumNotFoo(v)) {
+	//         var zero EnumNotFoo
+	//         return zero, false
+	//     }
+	//     return EnumNotFoo(v), true
+	// }
+	to := &dst.FuncDecl{
+		Name: dst.NewIdent("To" + subset),
+		Type: &dst.FuncType{
+			Params: &dst.FieldList{List: []*dst.Field{{
+				Names: []*dst.Ident{dst.NewIdent("v")},
+				Type:  dst.NewIdent(param.Name),
+			}}},
+			Results: &dst.FieldList{List: []*dst.Field{
+				{Type: dst.NewIdent(subset)},
+				{Type: dst.NewIdent("bool")},
+			}},
+		},
+		Body: &dst.BlockStmt{List: []dst.Stmt{
+			&dst.IfStmt{
+				Cond: &dst.UnaryExpr{
+					Op: token.NOT,
+					X: dstCall(
+						dstSel("slices", "Contains"),
+						&dst.SliceExpr{X: dst.NewIdent(allIdent)},
+						dstCall(dst.NewIdent(subset), dst.NewIdent("v")),
+					),
+				},
+				Body: &dst.BlockStmt{List: []dst.Stmt{
+					&dst.DeclStmt{Decl: &dst.GenDecl{
+						Tok: token.VAR,
+						Specs: []dst.Spec{&dst.ValueSpec{
+							Names: []*dst.Ident{dst.NewIdent("zero")},
+							Type:  dst.NewIdent(subset),
+						}},
+					}},
+					&dst.ReturnStmt{Results: []dst.Expr{dst.NewIdent("zero"), dst.NewIdent("false")}},
+				}},
+			},
+			&dst.ReturnStmt{Results: []dst.Expr{
+				dstCall(dst.NewIdent(subset), dst.NewIdent("v")),
+				dst.NewIdent("true"),
+			}},
+		}},
+	}
+	to.Decs.Start = dst.Decorations{
+		"// To" + subset + " converts v to " + subset + ".",
+		"// It reports false if v is not a variant of " + subset + ".",
+	}
+
+	// func (v EnumNotFoo) Enum() Enum { return Enum(v) }
+	widen := dstMethod(
+		dst.NewIdent(subset),
+		param.Name,
+		nil,
+		[]*dst.Field{{Type: dst.NewIdent(param.Name)}},
+		&dst.ReturnStmt{Results: []dst.Expr{dstCall(dst.NewIdent(param.Name), dst.NewIdent("v"))}},
+	)
+	widen.Decs.Start = dst.Decorations{"// " + param.Name + " widens v back to " + param.Name + "."}
+
+	decls := []dst.Decl{typeDecl, constDecl, allDecl, to, widen}
+	if param.IsInteger() {
+		// func (v EnumNotFoo) String() string { return Enum(v).String() }
+		decls = append(decls, dstMethod(
+			dst.NewIdent(subset),
+			"String",
+			nil,
+			[]*dst.Field{{Type: dst.NewIdent("string")}},
+			&dst.ReturnStmt{Results: []dst.Expr{dstCall(&dst.SelectorExpr{
+				X:   dstCall(dst.NewIdent(param.Name), dst.NewIdent("v")),
+				Sel: dst.NewIdent("String"),
+			})}},
+		))
+	}
+	return decls
+}
+
+func dstIotaExpr(start int64) dst.Expr {
+	switch {
+	case start > 0:
+		return &dst.BinaryExpr{X: dst.NewIdent("iota"), Op: token.ADD, Y: dstUintLit(uint64(start))}
+	case start < 0:
+		return &dst.BinaryExpr{X: dst.NewIdent("iota"), Op: token.SUB, Y: dstUintLit(uint64(-(start + 1)) + 1)}
+	}
+	return dst.NewIdent("iota")
+}
+
+func dstUintLit(v uint64) *dst.BasicLit {
+	return &dst.BasicLit{Kind: token.INT, Value: strconv.FormatUint(v, 10)}
+}
+
+func dstNameTable(param EnumParam) []dst.Decl {
+	table := makeNameTable(param.Variants)
+	nameIdent := "_" + param.Name + "Name"
+	indexIdent := "_" + param.Name + "Index"
+
+	// const _StatusName = "foobar"
+	nameDecl := &dst.GenDecl{
+		Tok: token.CONST,
+		Specs: []dst.Spec{&dst.ValueSpec{
+			Names:  []*dst.Ident{dst.NewIdent(nameIdent)},
+			Values: []dst.Expr{&dst.BasicLit{Kind: token.STRING, Value: strconv.Quote(table.Names)}},
+		}},
+	}
+
+	// var _StatusIndex = [...]uint8{0, 3, 6}
+	indexElts := make([]dst.Expr, len(table.Index))
+	for i, idx := range table.Index {
+		indexElts[i] = dstUintLit(uint64(idx))
+	}
+	indexDecl := &dst.GenDecl{
+		Tok: token.VAR,
+		Specs: []dst.Spec{&dst.ValueSpec{
+			Names: []*dst.Ident{dst.NewIdent(indexIdent)},
+			Values: []dst.Expr{&dst.CompositeLit{
+				Type: &dst.ArrayType{Len: &dst.Ellipsis{}, Elt: dst.NewIdent(table.IndexType)},
+				Elts: indexElts,
+			}},
+		}},
+	}
+
+	return []dst.Decl{nameDecl, indexDecl}
+}
+
+// dstVariantName returns _PermName[_PermIndex[i]:_PermIndex[i+1]].
+func dstVariantName(param EnumParam, i string) dst.Expr {
+	indexIdent := "_" + param.Name + "Index"
+	return &dst.SliceExpr{
+		X:    dst.NewIdent("_" + param.Name + "Name"),
+		Low:  &dst.IndexExpr{X: dst.NewIdent(indexIdent), Index: dst.NewIdent(i)},
+		High: &dst.IndexExpr{X: dst.NewIdent(indexIdent), Index: &dst.BinaryExpr{X: dst.NewIdent(i), Op: token.ADD, Y: dstUintLit(1)}},
+	}
+}
+
+func dstFlags(param EnumParam) []dst.Decl {
+	decls := dstNameTable(param)
+
+	// method returns func (v Perm) <name>(flag Perm) <result> { return <body> }
+	method := func(doc, name string, result dst.Expr, body dst.Expr) *dst.FuncDecl {
+		m := dstMethod(
+			dst.NewIdent(param.Name),
+			name,
+			[]*dst.Field{{Names: []*dst.Ident{dst.NewIdent("flag")}, Type: dst.NewIdent(param.Name)}},
+			[]*dst.Field{{Type: result}},
+			&dst.ReturnStmt{Results: []dst.Expr{body}},
+		)
+		m.Decs.Start = dst.Decorations{"// " + doc}
+		return m
+	}
+	op := func(x string, tok token.Token, y string) *dst.BinaryExpr {
+		return &dst.BinaryExpr{X: dst.NewIdent(x), Op: tok, Y: dst.NewIdent(y)}
+	}
+	decls = append(
+		decls,
+		method(
+			"Has reports whether all flags set in flag are also set in v.",
+			"Has", dst.NewIdent("bool"),
+			&dst.BinaryExpr{X: op("v", token.AND, "flag"), Op: token.EQL, Y: dst.NewIdent("flag")},
+		),
+		method("Set returns v with flags in flag set.", "Set", dst.NewIdent(param.Name), op("v", token.OR, "flag")),
+		method("Clear returns v with flags in flag cleared.", "Clear", dst.NewIdent(param.Name), op("v", token.AND_NOT, "flag")),
+		method("Toggle returns v with flags in flag toggled.", "Toggle", dst.NewIdent(param.Name), op("v", token.XOR, "flag")),
+	)
+
+	// func (v Perm) String() string
+	stringer := dstMethod(
+		dst.NewIdent(param.Name),
+		"String",
+		nil,
+		[]*dst.Field{{Type: dst.NewIdent("string")}},
+		&dst.DeclStmt{Decl: &dst.GenDecl{
+			Tok: token.VAR,
+			Specs: []dst.Spec{&dst.ValueSpec{
+				Names: []*dst.Ident{dst.NewIdent("names")},
+				Type:  &dst.ArrayType{Elt: dst.NewIdent("string")},
+			}},
+		}},
+		&dst.RangeStmt{
+			Key:   dst.NewIdent("i"),
+			Value: dst.NewIdent("flag"),
+			Tok:   token.DEFINE,
+			X:     dst.NewIdent("_" + param.Name + "All"),
+			Body: &dst.BlockStmt{List: []dst.Stmt{&dst.IfStmt{
+				Cond: &dst.BinaryExpr{X: op("v", token.AND, "flag"), Op: token.NEQ, Y: dstUintLit(0)},
+				Body: &dst.BlockStmt{List: []dst.Stmt{&dst.AssignStmt{
+					Lhs: []dst.Expr{dst.NewIdent("names")},
+					Tok: token.ASSIGN,
+					Rhs: []dst.Expr{dstCall(dst.NewIdent("append"), dst.NewIdent("names"), dstVariantName(param, "i"))},
+				}}},
+			}}},
+		},
+		&dst.IfStmt{
+			Init: &dst.AssignStmt{
+				Lhs: []dst.Expr{dst.NewIdent("rest")},
+				Tok: token.DEFINE,
+				Rhs: []dst.Expr{op("v", token.AND_NOT, "_"+param.Name+"Mask")},
+			},
+			Cond: &dst.BinaryExpr{X: dst.NewIdent("rest"), Op: token.NEQ, Y: dstUintLit(0)},
+			Body: &dst.BlockStmt{List: []dst.Stmt{&dst.AssignStmt{
+				Lhs: []dst.Expr{dst.NewIdent("names")},
+				Tok: token.ASSIGN,
+				Rhs: []dst.Expr{dstCall(
+					dst.NewIdent("append"),
+					dst.NewIdent("names"),
+					&dst.BinaryExpr{
+						X: &dst.BinaryExpr{
+							X:  dstStringLit(param.Name + "(0x"),
+							Op: token.ADD,
+							Y: dstCall(
+								dstSel("strconv", "FormatUint"),
+								dstCall(dst.NewIdent("uint64"), dst.NewIdent("rest")),
+								dstUintLit(16),
+							),
+						},
+						Op: token.ADD,
+						Y:  dstStringLit(")"),
+					},
+				)},
+			}}},
+		},
+		&dst.ReturnStmt{Results: []dst.Expr{dstCall(dstSel("strings", "Join"), dst.NewIdent("names"), dstStringLit("|"))}},
+	)
+	stringer.Decs.Start = dst.Decorations{`// String returns names of flags set in v joined with "|".`}
+
+	// func ParsePerm(s string) (Perm, error)
+	parse := &dst.FuncDecl{
+		Name: dst.NewIdent("Parse" + param.Name),
+		Type: &dst.FuncType{
+			Params:  &dst.FieldList{List: []*dst.Field{{Names: []*dst.Ident{dst.NewIdent("s")}, Type: dst.NewIdent("string")}}},
+			Results: &dst.FieldList{List: []*dst.Field{{Type: dst.NewIdent(param.Name)}, {Type: dst.NewIdent("error")}}},
+		},
+		Body: &dst.BlockStmt{List: []dst.Stmt{
+			&dst.DeclStmt{Decl: &dst.GenDecl{
+				Tok:   token.VAR,
+				Specs: []dst.Spec{&dst.ValueSpec{Names: []*dst.Ident{dst.NewIdent("v")}, Type: dst.NewIdent(param.Name)}},
+			}},
+			&dst.IfStmt{
+				Cond: &dst.BinaryExpr{X: dst.NewIdent("s"), Op: token.EQL, Y: dstStringLit("")},
+				Body: &dst.BlockStmt{List: []dst.Stmt{&dst.ReturnStmt{Results: []dst.Expr{dst.NewIdent("v"), dst.NewIdent("nil")}}}},
+			},
+			&dst.LabeledStmt{
+				Label: dst.NewIdent("NAMES"),
+				Stmt: &dst.RangeStmt{
+					Key:   dst.NewIdent("_"),
+					Value: dst.NewIdent("name"),
+					Tok:   token.DEFINE,
+					X:     dstCall(dstSel("strings", "Split"), dst.NewIdent("s"), dstStringLit("|")),
+					Body: &dst.BlockStmt{List: []dst.Stmt{
+						&dst.RangeStmt{
+							Key:   dst.NewIdent("i"),
+							Value: dst.NewIdent("flag"),
+							Tok:   token.DEFINE,
+							X:     dst.NewIdent("_" + param.Name + "All"),
+							Body: &dst.BlockStmt{List: []dst.Stmt{&dst.IfStmt{
+								Cond: &dst.BinaryExpr{X: dstVariantName(param, "i"), Op: token.EQL, Y: dst.NewIdent("name")},
+								Body: &dst.BlockStmt{List: []dst.Stmt{
+									&dst.AssignStmt{
+										Lhs: []dst.Expr{dst.NewIdent("v")},
+										Tok: token.OR_ASSIGN,
+										Rhs: []dst.Expr{dst.NewIdent("flag")},
+									},
+									&dst.BranchStmt{Tok: token.CONTINUE, Label: dst.NewIdent("NAMES")},
+								}},
+							}}},
+						},
+						&dst.ReturnStmt{Results: []dst.Expr{
+							dstUintLit(0),
+							dstCall(
+								dstSel("fmt", "Errorf"),
+								dstStringLit("parsing "+param.Name+": unknown flag %q"),
+								dst.NewIdent("name"),
+							),
+						}},
+					}},
+				},
+			},
+			&dst.ReturnStmt{Results: []dst.Expr{dst.NewIdent("v"), dst.NewIdent("nil")}},
+		}},
+	}
+	parse.Decs.Start = dst.Decorations{"// Parse" + param.Name + ` parses names of flags joined with "|", e.g. "foo|bar", into ` + param.Name + "."}
+
+	return append(decls, stringer, parse)
+}
+
+func dstStringer(param EnumParam) []dst.Decl {
+	nameIdent := "_" + param.Name + "Name"
+	indexIdent := "_" + param.Name + "Index"
+
+	// i := uint64(v) - 1
+	var index dst.Expr = &dst.CallExpr{Fun: dst.NewIdent("uint64"), Args: []dst.Expr{dst.NewIdent("v")}}
+	switch {
+	case param.Start > 0:
+		index = &dst.BinaryExpr{X: index, Op: token.SUB, Y: dstUintLit(uint64(param.Start))}
+	case param.Start < 0:
+		index = &dst.BinaryExpr{X: index, Op: token.ADD, Y: dstUintLit(uint64(-(param.Start + 1)) + 1)}
+	}
+	assign := &dst.AssignStmt{
+		Lhs: []dst.Expr{dst.NewIdent("i")},
+		Tok: token.DEFINE,
+		Rhs: []dst.Expr{index},
+	}
+
+	// strconv.FormatInt(int64(v), 10)
+	formatFunc, formatConv := "FormatInt", "int64"
+	if param.IsUnsigned() {
+		formatFunc, formatConv = "FormatUint", "uint64"
+	}
+	format := &dst.CallExpr{
+		Fun: &dst.SelectorExpr{X: dst.NewIdent("strconv"), Sel: dst.NewIdent(formatFunc)},
+		Args: []dst.Expr{
+			&dst.CallExpr{Fun: dst.NewIdent(formatConv), Args: []dst.Expr{dst.NewIdent("v")}},
+			&dst.BasicLit{Kind: token.INT, Value: "10"},
+		},
+	}
+
+	// if i >= uint64(len(_StatusIndex)-1) {
+	//     return "Status(" + strconv.FormatInt(int64(v), 10) + ")"
+	// }
+	outOfRange := &dst.IfStmt{
+		Cond: &dst.BinaryExpr{
+			X:  dst.NewIdent("i"),
+			Op: token.GEQ,
+			Y: &dst.CallExpr{
+				Fun: dst.NewIdent("uint64"),
+				Args: []dst.Expr{&dst.BinaryExpr{
+					X:  &dst.CallExpr{Fun: dst.NewIdent("len"), Args: []dst.Expr{dst.NewIdent(indexIdent)}},
+					Op: token.SUB,
+					Y:  dstUintLit(1),
+				}},
+			},
+		},
+		Body: &dst.BlockStmt{List: []dst.Stmt{&dst.ReturnStmt{Results: []dst.Expr{
+			&dst.BinaryExpr{
+				X: &dst.BinaryExpr{
+					X:  &dst.BasicLit{Kind: token.STRING, Value: strconv.Quote(param.Name + "(")},
+					Op: token.ADD,
+					Y:  format,
+				},
+				Op: token.ADD,
+				Y:  &dst.BasicLit{Kind: token.STRING, Value: strconv.Quote(")")},
+			},
+		}}}},
+	}
+
+	// return _StatusName[_StatusIndex[i]:_StatusIndex[i+1]]
+	ret := &dst.ReturnStmt{Results: []dst.Expr{&dst.SliceExpr{
+		X:    dst.NewIdent(nameIdent),
+		Low:  &dst.IndexExpr{X: dst.NewIdent(indexIdent), Index: dst.NewIdent("i")},
+		High: &dst.IndexExpr{X: dst.NewIdent(indexIdent), Index: &dst.BinaryExpr{X: dst.NewIdent("i"), Op: token.ADD, Y: dstUintLit(1)}},
+	}}}
+
+	stringer := &dst.FuncDecl{
+		Recv: &dst.FieldList{List: []*dst.Field{{
+			Names: []*dst.Ident{dst.NewIdent("v")},
+			Type:  dst.NewIdent(param.Name),
+		}}},
+		Name: dst.NewIdent("String"),
+		Type: &dst.FuncType{
+			Params:  &dst.FieldList{},
+			Results: &dst.FieldList{List: []*dst.Field{{Type: dst.NewIdent("string")}}},
+		},
+		Body: &dst.BlockStmt{List: []dst.Stmt{assign, outOfRange, ret}},
+	}
+
+	return append(dstNameTable(param), stringer)
+}
+
+func dstCall(fun dst.Expr, args ...dst.Expr) *dst.CallExpr {
+	return &dst.CallExpr{Fun: fun, Args: args}
+}
+
+func dstSel(x, sel string) *dst.SelectorExpr {
+	return &dst.SelectorExpr{X: dst.NewIdent(x), Sel: dst.NewIdent(sel)}
+}
+
+func dstStringLit(s string) *dst.BasicLit {
+	return &dst.BasicLit{Kind: token.STRING, Value: strconv.Quote(s)}
+}
+
+func dstByteSlice() *dst.ArrayType {
+	return &dst.ArrayType{Elt: dst.NewIdent("byte")}
+}
+
+func dstMethod(recv dst.Expr, name string, params, results []*dst.Field, body ...dst.Stmt) *dst.FuncDecl {
+	if len(body) > 0 {
+		// Prevents the printer from squashing a short body into a single line.
+		body[0].Decorations().Before = dst.NewLine
+		body[len(body)-1].Decorations().After = dst.NewLine
+	}
+	return &dst.FuncDecl{
+		Recv: &dst.FieldList{List: []*dst.Field{{Names: []*dst.Ident{dst.NewIdent("v")}, Type: recv}}},
+		Name: dst.NewIdent(name),
+		Type: &dst.FuncType{
+			Params:  &dst.FieldList{List: params},
+			Results: &dst.FieldList{List: results},
+		},
+		Body: &dst.BlockStmt{List: body},
+	}
+}
+
+// dstReturnIfErr returns the statement
+//
+//	if err != nil {
+//		return results...
+//	}
+func dstReturnIfErr(results ...dst.Expr) *dst.IfStmt {
+	return &dst.IfStmt{
+		Cond: &dst.BinaryExpr{X: dst.NewIdent("err"), Op: token.NEQ, Y: dst.NewIdent("nil")},
+		Body: &dst.BlockStmt{List: []dst.Stmt{&dst.ReturnStmt{Results: results}}},
+	}
+}
+
+// dstText returns expression converting v, a value of the enum, into its text representation.
+func dstText(param EnumParam, v string) dst.Expr {
+	if param.IsInteger() {
+		return dstCall(dstSel(v, "String"))
+	}
+	return dstCall(dst.NewIdent("string"), dst.NewIdent(v))
+}
+
+// dstNewInvalidError returns &InvalidEnumError{Value: value}.
+func dstNewInvalidError(param EnumParam, value dst.Expr) dst.Expr {
+	return &dst.UnaryExpr{
+		Op: token.AND,
+		X: &dst.CompositeLit{
+			Type: dst.NewIdent("Invalid" + param.Name + "Error"),
+			Elts: []dst.Expr{&dst.KeyValueExpr{Key: dst.NewIdent("Value"), Value: value}},
+		},
+	}
+}
+
+func dstInvalidError(param EnumParam) []dst.Decl {
+	errName := "Invalid" + param.Name + "Error"
+
+	// type InvalidEnumError struct {
+	//     Value string
+	// }
+	errDecl := &dst.GenDecl{
+		Tok: token.TYPE,
+		Specs: []dst.Spec{&dst.TypeSpec{
+			Name: dst.NewIdent(errName),
+			Type: &dst.StructType{Fields: &dst.FieldList{List: []*dst.Field{{
+				Names: []*dst.Ident{dst.NewIdent("Value")},
+				Type:  dst.NewIdent("string"),
+			}}}},
+		}},
+	}
+	errDecl.Decs.Start = dst.Decorations{
+		"// " + errName + " is returned when marshaling or unmarshaling a value that is not a variant of " + param.Name + ".",
+	}
+
+	// func (e *InvalidEnumError) Error() string
+	errMethod := dstMethod(
+		&dst.StarExpr{X: dst.NewIdent(errName)},
+		"Error",
+		nil,
+		[]*dst.Field{{Type: dst.NewIdent("string")}},
+		&dst.AssignStmt{
+			Lhs: []dst.Expr{dst.NewIdent("allowed")},
+			Tok: token.DEFINE,
+			Rhs: []dst.Expr{dstCall(
+				dst.NewIdent("make"),
+				&dst.ArrayType{Elt: dst.NewIdent("string")},
+				dstCall(dst.NewIdent("len"), dst.NewIdent("_"+param.Name+"All")),
+			)},
+		},
+		&dst.RangeStmt{
+			Key:   dst.NewIdent("i"),
+			Value: dst.NewIdent("v"),
+			Tok:   token.DEFINE,
+			X:     dst.NewIdent("_" + param.Name + "All"),
+			Body: &dst.BlockStmt{List: []dst.Stmt{&dst.AssignStmt{
+				Lhs: []dst.Expr{&dst.IndexExpr{X: dst.NewIdent("allowed"), Index: dst.NewIdent("i")}},
+				Tok: token.ASSIGN,
+				Rhs: []dst.Expr{dstText(param, "v")},
+			}}},
+		},
+		&dst.ReturnStmt{Results: []dst.Expr{dstCall(
+			dstSel("fmt", "Sprintf"),
+			dstStringLit("invalid "+param.Name+" %q: allowed variants are %q"),
+			dstSel("e", "Value"),
+			dst.NewIdent("allowed"),
+		)}},
+	)
+	errMethod.Recv.List[0].Names[0].Name = "e"
+
+	return []dst.Decl{errDecl, errMethod}
+}
+
+func dstMarshal(param EnumParam) []dst.Decl {
+	allIdent := "_" + param.Name + "All"
+
+	text := func(v string) dst.Expr {
+		return dstText(param, v)
+	}
+	newErr := func(value dst.Expr) dst.Expr {
+		return dstNewInvalidError(param, value)
+	}
+	bytesAndErr := func() []*dst.Field {
+		return []*dst.Field{{Type: dstByteSlice()}, {Type: dst.NewIdent("error")}}
+	}
+
+	// func (v Enum) MarshalText() ([]byte, error)
+	var marshaled dst.Expr = dst.NewIdent("v")
+	if param.IsInteger() {
+		marshaled = text("v")
+	}
+	marshalText := dstMethod(
+		dst.NewIdent(param.Name),
+		"MarshalText",
+		nil,
+		bytesAndErr(),
+		&dst.IfStmt{
+			Cond: &dst.UnaryExpr{Op: token.NOT, X: dstCall(dst.NewIdent("Is"+param.Name), dst.NewIdent("v"))},
+			Body: &dst.BlockStmt{List: []dst.Stmt{
+				&dst.ReturnStmt{Results: []dst.Expr{dst.NewIdent("nil"), newErr(text("v"))}},
+			}},
+		},
+		&dst.ReturnStmt{Results: []dst.Expr{dstCall(dstByteSlice(), marshaled), dst.NewIdent("nil")}},
+	)
+
+	// func (v *Enum) UnmarshalText(text []byte) error
+	var unmarshalTextBody []dst.Stmt
+	if param.Flags {
+		unmarshalTextBody = []dst.Stmt{
+			&dst.AssignStmt{
+				Lhs: []dst.Expr{dst.NewIdent("parsed"), dst.NewIdent("err")},
+				Tok: token.DEFINE,
+				Rhs: []dst.Expr{dstCall(dst.NewIdent("Parse"+param.Name), dstCall(dst.NewIdent("string"), dst.NewIdent("text")))},
+			},
+			dstReturnIfErr(newErr(dstCall(dst.NewIdent("string"), dst.NewIdent("text")))),
+			&dst.AssignStmt{
+				Lhs: []dst.Expr{&dst.StarExpr{X: dst.NewIdent("v")}},
+				Tok: token.ASSIGN,
+				Rhs: []dst.Expr{dst.NewIdent("parsed")},
+			},
+			&dst.ReturnStmt{Results: []dst.Expr{dst.NewIdent("nil")}},
+		}
+	} else {
+		unmarshalTextBody = []dst.Stmt{
+			&dst.RangeStmt{
+				Key:   dst.NewIdent("_"),
+				Value: dst.NewIdent("variant"),
+				Tok:   token.DEFINE,
+				X:     dst.NewIdent(allIdent),
+				Body: &dst.BlockStmt{List: []dst.Stmt{&dst.IfStmt{
+					Cond: &dst.BinaryExpr{
+						X:  text("variant"),
+						Op: token.EQL,
+						Y:  dstCall(dst.NewIdent("string"), dst.NewIdent("text")),
+					},
+					Body: &dst.BlockStmt{List: []dst.Stmt{
+						&dst.AssignStmt{
+							Lhs: []dst.Expr{&dst.StarExpr{X: dst.NewIdent("v")}},
+							Tok: token.ASSIGN,
+							Rhs: []dst.Expr{dst.NewIdent("variant")},
+						},
+						&dst.ReturnStmt{Results: []dst.Expr{dst.NewIdent("nil")}},
+					}},
+				}}},
+			},
+			&dst.ReturnStmt{Results: []dst.Expr{newErr(dstCall(dst.NewIdent("string"), dst.NewIdent("text")))}},
+		}
+	}
+	unmarshalText := dstMethod(
+		&dst.StarExpr{X: dst.NewIdent(param.Name)},
+		"UnmarshalText",
+		[]*dst.Field{{Names: []*dst.Ident{dst.NewIdent("text")}, Type: dstByteSlice()}},
+		[]*dst.Field{{Type: dst.NewIdent("error")}},
+		unmarshalTextBody...,
+	)
+
+	// func (v Enum) MarshalJSON() ([]byte, error)
+	marshalJSON := dstMethod(
+		dst.NewIdent(param.Name),
+		"MarshalJSON",
+		nil,
+		bytesAndErr(),
+		&dst.AssignStmt{
+			Lhs: []dst.Expr{dst.NewIdent("text"), dst.NewIdent("err")},
+			Tok: token.DEFINE,
+			Rhs: []dst.Expr{dstCall(dstSel("v", "MarshalText"))},
+		},
+		dstReturnIfErr(dst.NewIdent("nil"), dst.NewIdent("err")),
+		&dst.ReturnStmt{Results: []dst.Expr{dstCall(
+			dstSel("json", "Marshal"),
+			dstCall(dst.NewIdent("string"), dst.NewIdent("text")),
+		)}},
+	)
+
+	// func (v *Enum) UnmarshalJSON(data []byte) error
+	unmarshalJSON := dstMethod(
+		&dst.StarExpr{X: dst.NewIdent(param.Name)},
+		"UnmarshalJSON",
+		[]*dst.Field{{Names: []*dst.Ident{dst.NewIdent("data")}, Type: dstByteSlice()}},
+		[]*dst.Field{{Type: dst.NewIdent("error")}},
+		&dst.IfStmt{
+			Cond: &dst.BinaryExpr{
+				X:  dstCall(dst.NewIdent("string"), dst.NewIdent("data")),
+				Op: token.EQL,
+				Y:  dstStringLit("null"),
+			},
+			Body: &dst.BlockStmt{List: []dst.Stmt{&dst.ReturnStmt{Results: []dst.Expr{dst.NewIdent("nil")}}}},
+			Decs: dst.IfStmtDecorations{NodeDecs: dst.NodeDecs{Start: dst.Decorations{"// As encoding/json does, null is a no-op."}}},
+		},
+		&dst.DeclStmt{Decl: &dst.GenDecl{
+			Tok: token.VAR,
+			Specs: []dst.Spec{&dst.ValueSpec{
+				Names: []*dst.Ident{dst.NewIdent("s")},
+				Type:  dst.NewIdent("string"),
+			}},
+		}},
+		&dst.AssignStmt{
+			Lhs: []dst.Expr{dst.NewIdent("err")},
+			Tok: token.DEFINE,
+			Rhs: []dst.Expr{dstCall(
+				dstSel("json", "Unmarshal"),
+				dst.NewIdent("data"),
+				&dst.UnaryExpr{Op: token.AND, X: dst.NewIdent("s")},
+			)},
+		},
+		dstReturnIfErr(dst.NewIdent("err")),
+		&dst.ReturnStmt{Results: []dst.Expr{dstCall(
+			dstSel("v", "UnmarshalText"),
+			dstCall(dstByteSlice(), dst.NewIdent("s")),
+		)}},
+	)
+
+	return []dst.Decl{marshalText, unmarshalText, marshalJSON, unmarshalJSON}
+}
+
+func dstSQL(param EnumParam) []dst.Decl {
+	nullName := "Null" + param.Name
+	driverValueAndErr := func() []*dst.Field {
+		return []*dst.Field{{Type: dstSel("driver", "Value")}, {Type: dst.NewIdent("error")}}
+	}
+	srcParam := func() []*dst.Field {
+		return []*dst.Field{{Names: []*dst.Ident{dst.NewIdent("src")}, Type: dst.NewIdent("any")}}
+	}
+	errResult := func() []*dst.Field {
+		return []*dst.Field{{Type: dst.NewIdent("error")}}
+	}
+	assign := func(tok token.Token, lhs []dst.Expr, rhs ...dst.Expr) *dst.AssignStmt {
+		return &dst.AssignStmt{Lhs: lhs, Tok: tok, Rhs: rhs}
+	}
+	ret := func(results ...dst.Expr) *dst.ReturnStmt {
+		return &dst.ReturnStmt{Results: results}
+	}
+	block := func(stmts ...dst.Stmt) *dst.BlockStmt {
+		return &dst.BlockStmt{List: stmts}
+	}
+	conv := func(typ, v string) dst.Expr {
+		return dstCall(dst.NewIdent(typ), dst.NewIdent(v))
+	}
+	notIs := func(v dst.Expr) dst.Expr {
+		return &dst.UnaryExpr{Op: token.NOT, X: dstCall(dst.NewIdent("Is"+param.Name), v)}
+	}
+	deref := func(v string) dst.Expr {
+		return &dst.StarExpr{X: dst.NewIdent(v)}
+	}
+
+	// func (v Enum) Value() (driver.Value, error)
+	stored := conv("string", "v")
+	if param.IsInteger() {
+		stored = conv("int64", "v")
+	}
+	value := dstMethod(
+		dst.NewIdent(param.Name),
+		"Value",
+		nil,
+		driverValueAndErr(),
+		&dst.IfStmt{
+			Cond: notIs(dst.NewIdent("v")),
+			Body: block(ret(dst.NewIdent("nil"), dstNewInvalidError(param, dstText(param, "v")))),
+		},
+		ret(stored, dst.NewIdent("nil")),
+	)
+
+	// func (v *Enum) Scan(src any) error
+	unsupported := func() dst.Stmt {
+		return ret(dstCall(
+			dstSel("fmt", "Errorf"),
+			dstStringLit("scanning "+param.Name+": unsupported type %T"),
+			dst.NewIdent("src"),
+		))
+	}
+	typeSwitch := func(cases ...dst.Stmt) *dst.TypeSwitchStmt {
+		cases = append(cases, &dst.CaseClause{Body: []dst.Stmt{unsupported()}})
+		return &dst.TypeSwitchStmt{
+			Assign: assign(token.DEFINE, []dst.Expr{dst.NewIdent("x")}, &dst.TypeAssertExpr{X: dst.NewIdent("src")}),
+			Body:   block(cases...),
+		}
+	}
+	caseClause := func(typ dst.Expr, body ...dst.Stmt) *dst.CaseClause {
+		return &dst.CaseClause{List: []dst.Expr{typ}, Body: body}
+	}
+	var scan *dst.FuncDecl
+	if param.IsInteger() {
+		parseInt := func(s dst.Expr) dst.Stmt {
+			return assign(
+				token.ASSIGN,
+				[]dst.Expr{dst.NewIdent("n"), dst.NewIdent("err")},
+				dstCall(dstSel("strconv", "ParseInt"), s, dstUintLit(10), dstUintLit(64)),
+			)
+		}
+		scan = dstMethod(
+			&dst.StarExpr{X: dst.NewIdent(param.Name)},
+			"Scan",
+			srcParam(),
+			errResult(),
+			&dst.DeclStmt{Decl: &dst.GenDecl{
+				Tok:    token.VAR,
+				Lparen: true,
+				Specs: []dst.Spec{
+					&dst.ValueSpec{Names: []*dst.Ident{dst.NewIdent("n")}, Type: dst.NewIdent("int64")},
+					&dst.ValueSpec{Names: []*dst.Ident{dst.NewIdent("err")}, Type: dst.NewIdent("error")},
+				},
+				Rparen: true,
+			}},
+			typeSwitch(
+				caseClause(dst.NewIdent("int64"), assign(token.ASSIGN, []dst.Expr{dst.NewIdent("n")}, dst.NewIdent("x"))),
+				caseClause(dst.NewIdent("string"), parseInt(dst.NewIdent("x"))),
+				caseClause(dstByteSlice(), parseInt(conv("string", "x"))),
+			),
+			dstReturnIfErr(dstCall(dstSel("fmt", "Errorf"), dstStringLit("scanning "+param.Name+": %w"), dst.NewIdent("err"))),
+			&dst.IfStmt{
+				Cond: &dst.BinaryExpr{
+					X: &dst.BinaryExpr{
+						X:  dstCall(dst.NewIdent("int64"), conv(param.Name, "n")),
+						Op: token.NEQ,
+						Y:  dst.NewIdent("n"),
+					},
+					Op: token.LOR,
+					Y:  notIs(conv(param.Name, "n")),
+				},
+				Body: block(ret(dstNewInvalidError(param, dstCall(dstSel("strconv", "FormatInt"), dst.NewIdent("n"), dstUintLit(10))))),
+			},
+			assign(token.ASSIGN, []dst.Expr{deref("v")}, conv(param.Name, "n")),
+			ret(dst.NewIdent("nil")),
+		)
+	} else {
+		scan = dstMethod(
+			&dst.StarExpr{X: dst.NewIdent(param.Name)},
+			"Scan",
+			srcParam(),
+			errResult(),
+			&dst.DeclStmt{Decl: &dst.GenDecl{
+				Tok:   token.VAR,
+				Specs: []dst.Spec{&dst.ValueSpec{Names: []*dst.Ident{dst.NewIdent("s")}, Type: dst.NewIdent("string")}},
+			}},
+			typeSwitch(
+				caseClause(dst.NewIdent("string"), assign(token.ASSIGN, []dst.Expr{dst.NewIdent("s")}, dst.NewIdent("x"))),
+				caseClause(dstByteSlice(), assign(token.ASSIGN, []dst.Expr{dst.NewIdent("s")}, conv("string", "x"))),
+			),
+			&dst.IfStmt{
+				Cond: notIs(conv(param.Name, "s")),
+				Body: block(ret(dstNewInvalidError(param, dst.NewIdent("s")))),
+			},
+			assign(token.ASSIGN, []dst.Expr{deref("v")}, conv(param.Name, "s")),
+			ret(dst.NewIdent("nil")),
+		)
+	}
+
+	// type NullEnum struct {
+	//     Enum  Enum
+	//     Valid bool
+	// }
+	nullDecl := &dst.GenDecl{
+		Tok: token.TYPE,
+		Specs: []dst.Spec{&dst.TypeSpec{
+			Name: dst.NewIdent(nullName),
+			Type: &dst.StructType{Fields: &dst.FieldList{List: []*dst.Field{
+				{Names: []*dst.Ident{dst.NewIdent(param.Name)}, Type: dst.NewIdent(param.Name)},
+				{Names: []*dst.Ident{dst.NewIdent("Valid")}, Type: dst.NewIdent("bool")},
+			}}},
+		}},
+	}
+	nullDecl.Decs.Start = dst.Decorations{"// " + nullName + " represents a " + param.Name + " that may be null."}
+
+	// func (n *NullEnum) Scan(src any) error
+	nullScan := dstMethod(
+		&dst.StarExpr{X: dst.NewIdent(nullName)},
+		"Scan",
+		srcParam(),
+		errResult(),
+		&dst.IfStmt{
+			Cond: &dst.BinaryExpr{X: dst.NewIdent("src"), Op: token.EQL, Y: dst.NewIdent("nil")},
+			Body: block(
+				assign(token.ASSIGN, []dst.Expr{deref("n")}, &dst.CompositeLit{Type: dst.NewIdent(nullName)}),
+				ret(dst.NewIdent("nil")),
+			),
+		},
+		assign(token.DEFINE, []dst.Expr{dst.NewIdent("err")}, dstCall(
+			&dst.SelectorExpr{X: dstSel("n", param.Name), Sel: dst.NewIdent("Scan")},
+			dst.NewIdent("src"),
+		)),
+		dstReturnIfErr(dst.NewIdent("err")),
+		assign(token.ASSIGN, []dst.Expr{dstSel("n", "Valid")}, dst.NewIdent("true")),
+		ret(dst.NewIdent("nil")),
+	)
+	nullScan.Recv.List[0].Names[0].Name = "n"
+
+	// func (n NullEnum) Value() (driver.Value, error)
+	nullValue := dstMethod(
+		dst.NewIdent(nullName),
+		"Value",
+		nil,
+		driverValueAndErr(),
+		&dst.IfStmt{
+			Cond: &dst.UnaryExpr{Op: token.NOT, X: dstSel("n", "Valid")},
+			Body: block(ret(dst.NewIdent("nil"), dst.NewIdent("nil"))),
+		},
+		ret(dstCall(&dst.SelectorExpr{X: dstSel("n", param.Name), Sel: dst.NewIdent("Value")})),
+	)
+	nullValue.Recv.List[0].Names[0].Name = "n"
+
+	return []dst.Decl{value, scan, nullDecl, nullScan, nullValue}
+}
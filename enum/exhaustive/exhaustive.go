@@ -0,0 +1,216 @@
+// Package exhaustive defines an analyzer reporting switch statements over enums generated by this project
+// which neither handle every variant nor have a default case.
+//
+// A named type is recognized as an enum if its package declares either
+//
+//	var _EnumAll = [...]Enum{EnumFoo, EnumBar}
+//
+// as the generators in the enum package do, or a const block marked by the rewriters with
+//
+//	//enum:generated_for=Enum
+//
+// Flags enums, those with _EnumMask, are ignored since switching over a combination of flags is not expected to be exhaustive.
+package exhaustive
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:      "enumexhaustive",
+	Doc:       "report switch statements over generated enums missing variants without a default case",
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	Run:       run,
+	FactTypes: []analysis.Fact{new(enumFact)},
+}
+
+const generatedForMarker = "//enum:generated_for="
+
+// enumFact is exported for the type name of each enum found,
+// so that switches in importing packages can be checked.
+type enumFact struct {
+	Variants []enumVariant
+}
+
+type enumVariant struct {
+	Name string
+	// Value is the exact string of the constant value of the variant.
+	Value string
+}
+
+func (*enumFact) AFact() {}
+
+func (f *enumFact) String() string {
+	names := make([]string, len(f.Variants))
+	for i, v := range f.Variants {
+		names[i] = v.Name
+	}
+	return "enum(" + strings.Join(names, ", ") + ")"
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	findEnums(pass)
+
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.Preorder([]ast.Node{(*ast.SwitchStmt)(nil)}, func(n ast.Node) {
+		checkSwitch(pass, n.(*ast.SwitchStmt))
+	})
+	return nil, nil
+}
+
+// findEnums exports enumFact for enums declared in the package of pass.
+func findEnums(pass *analysis.Pass) {
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			switch gen.Tok {
+			case token.VAR:
+				for _, spec := range gen.Specs {
+					findAllArray(pass, spec.(*ast.ValueSpec))
+				}
+			case token.CONST:
+				findMarkedConsts(pass, gen)
+			}
+		}
+	}
+}
+
+// findAllArray recognizes var _EnumAll = [...]Enum{EnumFoo, EnumBar}.
+func findAllArray(pass *analysis.Pass, spec *ast.ValueSpec) {
+	for i, name := range spec.Names {
+		typeName, ok := strings.CutPrefix(name.Name, "_")
+		if !ok || i >= len(spec.Values) {
+			continue
+		}
+		typeName, ok = strings.CutSuffix(typeName, "All")
+		if !ok {
+			continue
+		}
+		lit, ok := spec.Values[i].(*ast.CompositeLit)
+		if !ok {
+			continue
+		}
+		arr, ok := pass.TypesInfo.TypeOf(lit).(*types.Array)
+		if !ok {
+			continue
+		}
+		named, ok := types.Unalias(arr.Elem()).(*types.Named)
+		if !ok || named.Obj().Pkg() != pass.Pkg || named.Obj().Name() != typeName {
+			continue
+		}
+		exportEnum(pass, named.Obj(), lit.Elts)
+	}
+}
+
+// findMarkedConsts recognizes
+//
+//	//enum:generated_for=Enum
+//	const (
+//		EnumFoo Enum = "foo"
+//	)
+func findMarkedConsts(pass *analysis.Pass, gen *ast.GenDecl) {
+	if gen.Doc == nil {
+		return
+	}
+	var typeName string
+	for _, c := range gen.Doc.List {
+		if name, ok := strings.CutPrefix(c.Text, generatedForMarker); ok {
+			typeName = strings.TrimSpace(name)
+		}
+	}
+	if typeName == "" {
+		return
+	}
+	obj, ok := pass.Pkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return
+	}
+	var idents []ast.Expr
+	for _, spec := range gen.Specs {
+		for _, name := range spec.(*ast.ValueSpec).Names {
+			idents = append(idents, name)
+		}
+	}
+	exportEnum(pass, obj, idents)
+}
+
+func exportEnum(pass *analysis.Pass, obj *types.TypeName, idents []ast.Expr) {
+	if pass.ImportObjectFact(obj, new(enumFact)) {
+		// Found already by another declaration.
+		return
+	}
+	if pass.Pkg.Scope().Lookup("_"+obj.Name()+"Mask") != nil {
+		return
+	}
+	fact := new(enumFact)
+	for _, expr := range idents {
+		ident, ok := expr.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		c, ok := pass.TypesInfo.ObjectOf(ident).(*types.Const)
+		if !ok {
+			continue
+		}
+		fact.Variants = append(fact.Variants, enumVariant{Name: c.Name(), Value: c.Val().ExactString()})
+	}
+	if len(fact.Variants) == 0 {
+		return
+	}
+	pass.ExportObjectFact(obj, fact)
+}
+
+func checkSwitch(pass *analysis.Pass, sw *ast.SwitchStmt) {
+	if sw.Tag == nil {
+		return
+	}
+	named, ok := types.Unalias(pass.TypesInfo.TypeOf(sw.Tag)).(*types.Named)
+	if !ok {
+		return
+	}
+	fact := new(enumFact)
+	if !pass.ImportObjectFact(named.Obj(), fact) {
+		return
+	}
+
+	covered := make(map[string]bool)
+	for _, stmt := range sw.Body.List {
+		clause := stmt.(*ast.CaseClause)
+		if clause.List == nil {
+			// default
+			return
+		}
+		for _, expr := range clause.List {
+			tv, ok := pass.TypesInfo.Types[expr]
+			if !ok || tv.Value == nil {
+				continue
+			}
+			covered[tv.Value.ExactString()] = true
+		}
+	}
+
+	var missing []string
+	for _, v := range fact.Variants {
+		if !covered[v.Value] {
+			missing = append(missing, v.Name)
+		}
+	}
+	if len(missing) > 0 {
+		pass.Reportf(
+			sw.Pos(),
+			"missing cases in switch of type %s: %s",
+			types.TypeString(named, types.RelativeTo(pass.Pkg)),
+			strings.Join(missing, ", "),
+		)
+	}
+}
@@ -0,0 +1,12 @@
+package exhaustive_test
+
+import (
+	"testing"
+
+	"github.com/ngicks/go-example-code-generation/enum/exhaustive"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), exhaustive.Analyzer, "a", "b")
+}
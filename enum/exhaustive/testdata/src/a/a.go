@@ -0,0 +1,90 @@
+package a
+
+import (
+	"slices"
+
+	"b"
+)
+
+type Status uint8 // want Status:`enum\(StatusActive, StatusInactive, StatusDeleted\)`
+
+const (
+	StatusActive Status = iota + 1
+	StatusInactive
+	StatusDeleted
+)
+
+var _StatusAll = [...]Status{
+	StatusActive,
+	StatusInactive,
+	StatusDeleted,
+}
+
+func IsStatus(v Status) bool {
+	return slices.Contains(_StatusAll[:], v)
+}
+
+type Perm uint8
+
+const (
+	PermRead Perm = 1 << iota
+	PermWrite
+)
+
+const _PermMask Perm = PermRead | PermWrite
+
+var _PermAll = [...]Perm{
+	PermRead,
+	PermWrite,
+}
+
+type NotEnum string
+
+const (
+	NotEnumFoo NotEnum = "foo"
+	NotEnumBar NotEnum = "bar"
+)
+
+func status(v Status) {
+	switch v { // want `missing cases in switch of type Status: StatusDeleted`
+	case StatusActive, StatusInactive:
+	}
+
+	switch v {
+	case StatusActive:
+	case StatusInactive, StatusDeleted:
+	}
+
+	switch v {
+	case StatusActive:
+	default:
+	}
+
+	switch {
+	case v == StatusActive:
+	}
+
+	switch v { // want `missing cases in switch of type Status: StatusActive, StatusDeleted`
+	case 2:
+	}
+}
+
+func imported(v b.Enum) {
+	switch v { // want `missing cases in switch of type b.Enum: EnumBar, EnumBaz`
+	case b.EnumFoo:
+	}
+
+	switch v {
+	case b.EnumFoo, b.EnumBar, "baz":
+	}
+}
+
+func ignored(p Perm, n NotEnum) {
+	switch p {
+	case PermRead:
+	}
+
+	switch n {
+	case NotEnumFoo:
+	}
+}
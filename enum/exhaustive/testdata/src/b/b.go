@@ -0,0 +1,11 @@
+package b
+
+//enum:variants=foo,bar,baz
+type Enum string // want Enum:`enum\(EnumFoo, EnumBar, EnumBaz\)`
+
+//enum:generated_for=Enum
+const (
+	EnumFoo Enum = "foo"
+	EnumBar Enum = "bar"
+	EnumBaz Enum = "baz"
+)
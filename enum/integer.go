@@ -0,0 +1,56 @@
+package enum
+
+import (
+	"strconv"
+)
+
+// nameTable is a stringer-style compact table of variant names.
+// The name of i-th variant is Names[Index[i]:Index[i+1]].
+type nameTable struct {
+	Names     string
+	Index     []int
+	IndexType string
+}
+
+func makeNameTable(variants []string) nameTable {
+	var t nameTable
+	t.Index = make([]int, 0, len(variants)+1)
+	t.Index = append(t.Index, 0)
+	for _, v := range variants {
+		t.Names += v
+		t.Index = append(t.Index, len(t.Names))
+	}
+	switch {
+	case len(t.Names) < 1<<8:
+		t.IndexType = "uint8"
+	case len(t.Names) < 1<<16:
+		t.IndexType = "uint16"
+	default:
+		t.IndexType = "uint32"
+	}
+	return t
+}
+
+// startOffset returns the operator and operand to apply to iota
+// so that the first variant has value start, e.g. " + 1" for 1.
+// It returns an empty string for 0.
+func startOffset(start int64) string {
+	switch {
+	case start > 0:
+		return " + " + strconv.FormatInt(start, 10)
+	case start < 0:
+		return " - " + strconv.FormatUint(uint64(-(start+1))+1, 10)
+	}
+	return ""
+}
+
+// indexOffset is like startOffset but for converting a value back to an index into the name table.
+func indexOffset(start int64) string {
+	switch {
+	case start > 0:
+		return " - " + strconv.FormatInt(start, 10)
+	case start < 0:
+		return " + " + strconv.FormatUint(uint64(-(start+1))+1, 10)
+	}
+	return ""
+}
@@ -0,0 +1,554 @@
+package enum
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// JenniferBackend generates enums by building code with github.com/dave/jennifer.
+type JenniferBackend struct{}
+
+func (JenniferBackend) Name() string {
+	return "jennifer"
+}
+
+func (JenniferBackend) Generate(w io.Writer, param EnumParam) error {
+	err := param.Validate()
+	if err != nil {
+		return err
+	}
+
+	f := jen.NewFile(param.PackageName)
+
+	f.PackageComment("// Code generated by me. DO NOT EDIT.")
+
+	f.Type().Id(param.Name).Id(param.UnderlyingType()) // type Enum string
+
+	// const (
+	f.Const().DefsFunc(func(g *jen.Group) {
+		for i, variant := range param.Variants {
+			for _, line := range param.VariantDoc(variant) {
+				g.Comment(line) // // Foo is ...
+			}
+			if !param.IsInteger() {
+				g.
+					Id(param.VariantIdent(variant)). // EnumFoo
+					Id(param.Name).                  // Enum
+					Op("=").                         // =
+					Lit(variant)                     // "foo"\n
+				continue
+			}
+			if i > 0 {
+				g.Id(param.VariantIdent(variant)) // StatusBar
+				continue
+			}
+			if param.Flags {
+				g.
+					Id(param.VariantIdent(variant)). // PermFoo
+					Id(param.Name).                  // Perm
+					Op("=").                         // =
+					Lit(1).Op("<<").Iota()           // 1 << iota
+				continue
+			}
+			g.
+				Id(param.VariantIdent(variant)). // StatusFoo
+				Id(param.Name).                  // Status
+				Op("=").                         // =
+				Iota().                          // iota
+				Op(startOffset(param.Start))     // + 1
+		}
+	}) // )
+
+	if param.Flags {
+		f.Line()
+		// const _PermMask Perm = PermFoo | PermBar
+		f.Const().Id("_" + param.Name + "Mask").Id(param.Name).Op("=").Do(func(s *jen.Statement) {
+			if len(param.Variants) == 0 {
+				s.Lit(0)
+				return
+			}
+			for i, variant := range param.Variants {
+				if i > 0 {
+					s.Op("|")
+				}
+				s.Id(param.VariantIdent(variant))
+			}
+		})
+	}
+
+	// var _EnumAll = [...]Enum
+	f.Var().Id("_" + param.Name + "All").Op("=").Index(jen.Op("...")).Id(param.Name).
+		ValuesFunc(func(g *jen.Group) { // {
+			for _, variant := range param.Variants {
+				g.Line().Id(param.VariantIdent(variant)) // EnumFoo,
+			}
+			g.Line() // \n
+		}) // }
+
+	if param.Flags {
+		// func IsPerm(v Perm) bool { return v&^_PermMask == 0 }
+		f.Comment("Is" + param.Name + " reports whether v consists only of flags of " + param.Name + ".")
+		f.Func().Id("Is" + param.Name).Params(jen.Id("v").Id(param.Name)).Bool().Block(
+			jen.Return(jen.Id("v").Op("&^").Id("_" + param.Name + "Mask").Op("==").Lit(0)),
+		)
+	} else {
+		// func IsEnum(v Enum) bool
+		f.Func().Id("Is" + param.Name).Params(jen.Id("v").Id(param.Name)).Bool().Block( // {
+			jen.Return( // return
+				jen.Qual("slices", "Contains").Call( // slices.Contains
+					jen.Id("_"+param.Name+"All").Index(jen.Op(":")), // _EnumAll[:],
+					jen.Id("v"), // v,
+				),
+			),
+		) // }
+	}
+
+	f.Line()
+
+	switch {
+	case param.Flags:
+		jenFlags(f, param)
+	case param.IsInteger():
+		jenStringer(f, param)
+	}
+
+	if param.Marshal || param.SQL {
+		jenInvalidError(f, param)
+	}
+
+	if param.Marshal {
+		jenMarshal(f, param)
+	}
+
+	if param.SQL {
+		jenSQL(f, param)
+	}
+
+	for _, except := range param.Excepts {
+		except = fillName(except, param.Name)
+		// func IsEnumExceptFoo(v Enum) bool
+		f.Func().Id(param.ExceptIdent(except.ExceptName)).Params(jen.Id("v").Id(except.Name)).Bool().Block( // {
+			jen.Return( // return
+				jen.Op("!").Qual("slices", "Contains").Params( // !slice.Contains(
+					jen.Line().Index().Id(except.Name).ValuesFunc(func(g *jen.Group) { //[]Enum{
+						for _, e := range except.ExcludedValiants {
+							g.Line().Id(param.VariantIdent(e)) // EnumFoo,
+						}
+						g.Line()
+					}), // },
+					jen.Line().Id("v"), // v,
+					jen.Line(),
+				), // )
+			),
+		) // }
+		f.Line()
+
+		if except.Subset {
+			jenSubset(f, param, except)
+		}
+	}
+
+	var buf bytes.Buffer
+	err = f.Render(&buf)
+	if err != nil {
+		return err
+	}
+	return writeFormatted(w, buf.Bytes())
+}
+
+func jenSubset(f *jen.File, param EnumParam, except EnumExceptParam) {
+	subset := param.SubsetIdent(except.ExceptName)
+	variants := param.SubsetVariants(except)
+
+	// type EnumNotFoo Enum
+	f.Comment(subset + " is a subset of " + param.Name + " without variants excluded by except " + strconv.Quote(except.ExceptName) + ".")
+	f.Type().Id(subset).Id(param.Name)
+
+	f.Line()
+
+	// const (
+	//     EnumNotFooBar = EnumNotFoo(EnumBar)
+	// )
+	f.Const().DefsFunc(func(g *jen.Group) {
+		for _, variant := range variants {
+			g.Id(param.SubsetVariantIdent(except.ExceptName, variant)).Op("=").Id(subset).Call(jen.Id(param.VariantIdent(variant)))
+		}
+	})
+
+	f.Line()
+
+	// var _EnumNotFooAll = [...]EnumNotFoo{EnumNotFooBar}
+	f.Var().Id("_" + subset + "All").Op("=").Index(jen.Op("...")).Id(subset).ValuesFunc(func(g *jen.Group) {
+		for _, variant := range variants {
+			g.Line().Id(param.SubsetVariantIdent(except.ExceptName, variant))
+		}
+		g.Line()
+	})
+
+	f.Line()
+
+	// func ToEnumNotFoo(v Enum) (EnumNotFoo, bool)
+	f.Comment("To" + subset + " converts v to " + subset + ".")
+	f.Comment("It reports false if v is not a variant of " + subset + ".")
+	f.Func().Id("To"+subset).Params(jen.Id("v").Id(param.Name)).Params(jen.Id(subset), jen.Bool()).Block(
+		jen.If(jen.Op("!").Qual("slices", "Contains").Call(jen.Id("_"+subset+"All").Index(jen.Op(":")), jen.Id(subset).Call(jen.Id("v")))).Block(
+			jen.Var().Id("zero").Id(subset),
+			jen.Return(jen.Id("zero"), jen.False()),
+		),
+		jen.Return(jen.Id(subset).Call(jen.Id("v")), jen.True()),
+	)
+
+	f.Line()
+
+	// func (v EnumNotFoo) Enum() Enum
+	f.Comment(param.Name + " widens v back to " + param.Name + ".")
+	f.Func().Params(jen.Id("v").Id(subset)).Id(param.Name).Params().Id(param.Name).Block(
+		jen.Return(jen.Id(param.Name).Call(jen.Id("v"))),
+	)
+
+	f.Line()
+
+	if param.IsInteger() {
+		// func (v EnumNotFoo) String() string
+		f.Func().Params(jen.Id("v").Id(subset)).Id("String").Params().String().Block(
+			jen.Return(jen.Id(param.Name).Call(jen.Id("v")).Dot("String").Call()),
+		)
+
+		f.Line()
+	}
+}
+
+func jenNameTable(f *jen.File, param EnumParam) {
+	table := makeNameTable(param.Variants)
+
+	// const _StatusName = "foobar"
+	f.Const().Id("_" + param.Name + "Name").Op("=").Lit(table.Names)
+
+	// var _StatusIndex = [...]uint8{0, 3, 6}
+	f.Var().Id("_" + param.Name + "Index").Op("=").Index(jen.Op("...")).Id(table.IndexType).
+		ValuesFunc(func(g *jen.Group) {
+			for _, idx := range table.Index {
+				g.Lit(idx)
+			}
+		})
+}
+
+// jenVariantName returns _PermName[_PermIndex[i]:_PermIndex[i+1]].
+func jenVariantName(param EnumParam, i string) *jen.Statement {
+	return jen.Id("_"+param.Name+"Name").Index(
+		jen.Id("_"+param.Name+"Index").Index(jen.Id(i)),
+		jen.Id("_"+param.Name+"Index").Index(jen.Id(i).Op("+").Lit(1)),
+	)
+}
+
+func jenFlags(f *jen.File, param EnumParam) {
+	jenNameTable(f, param)
+
+	// method returns func (v Perm) <name>(flag Perm) <result> { return <body> }
+	method := func(doc, name string, result jen.Code, body jen.Code) {
+		f.Comment(doc)
+		f.Func().Params(jen.Id("v").Id(param.Name)).Id(name).Params(jen.Id("flag").Id(param.Name)).Add(result).Block(
+			jen.Return(body),
+		)
+		f.Line()
+	}
+	method("Has reports whether all flags set in flag are also set in v.", "Has", jen.Bool(), jen.Id("v").Op("&").Id("flag").Op("==").Id("flag"))
+	method("Set returns v with flags in flag set.", "Set", jen.Id(param.Name), jen.Id("v").Op("|").Id("flag"))
+	method("Clear returns v with flags in flag cleared.", "Clear", jen.Id(param.Name), jen.Id("v").Op("&^").Id("flag"))
+	method("Toggle returns v with flags in flag toggled.", "Toggle", jen.Id(param.Name), jen.Id("v").Op("^").Id("flag"))
+
+	// func (v Perm) String() string
+	f.Comment(`String returns names of flags set in v joined with "|".`)
+	f.Func().Params(jen.Id("v").Id(param.Name)).Id("String").Params().String().Block(
+		jen.Var().Id("names").Index().String(),
+		jen.For(jen.List(jen.Id("i"), jen.Id("flag")).Op(":=").Range().Id("_"+param.Name+"All")).Block(
+			jen.If(jen.Id("v").Op("&").Id("flag").Op("!=").Lit(0)).Block(
+				jen.Id("names").Op("=").Append(jen.Id("names"), jenVariantName(param, "i")),
+			),
+		),
+		jen.If(
+			jen.Id("rest").Op(":=").Id("v").Op("&^").Id("_"+param.Name+"Mask"),
+			jen.Id("rest").Op("!=").Lit(0),
+		).Block(
+			jen.Id("names").Op("=").Append(
+				jen.Id("names"),
+				jen.Lit(param.Name+"(0x").Op("+").
+					Qual("strconv", "FormatUint").Call(jen.Uint64().Call(jen.Id("rest")), jen.Lit(16)).
+					Op("+").Lit(")"),
+			),
+		),
+		jen.Return(jen.Qual("strings", "Join").Call(jen.Id("names"), jen.Lit("|"))),
+	)
+
+	f.Line()
+
+	// func ParsePerm(s string) (Perm, error)
+	f.Comment("Parse" + param.Name + ` parses names of flags joined with "|", e.g. "foo|bar", into ` + param.Name + ".")
+	f.Func().Id("Parse"+param.Name).Params(jen.Id("s").String()).Params(jen.Id(param.Name), jen.Error()).Block(
+		jen.Var().Id("v").Id(param.Name),
+		jen.If(jen.Id("s").Op("==").Lit("")).Block(
+			jen.Return(jen.Id("v"), jen.Nil()),
+		),
+		jen.Id("NAMES:"),
+		jen.For(jen.List(jen.Id("_"), jen.Id("name")).Op(":=").Range().Qual("strings", "Split").Call(jen.Id("s"), jen.Lit("|"))).Block(
+			jen.For(jen.List(jen.Id("i"), jen.Id("flag")).Op(":=").Range().Id("_"+param.Name+"All")).Block(
+				jen.If(jenVariantName(param, "i").Op("==").Id("name")).Block(
+					jen.Id("v").Op("|=").Id("flag"),
+					jen.Continue().Id("NAMES"),
+				),
+			),
+			jen.Return(jen.Lit(0), jen.Qual("fmt", "Errorf").Call(jen.Lit("parsing "+param.Name+": unknown flag %q"), jen.Id("name"))),
+		),
+		jen.Return(jen.Id("v"), jen.Nil()),
+	)
+
+	f.Line()
+}
+
+func jenStringer(f *jen.File, param EnumParam) {
+	jenNameTable(f, param)
+
+	format := jen.Qual("strconv", "FormatInt").Call(jen.Int64().Call(jen.Id("v")), jen.Lit(10))
+	if param.IsUnsigned() {
+		format = jen.Qual("strconv", "FormatUint").Call(jen.Uint64().Call(jen.Id("v")), jen.Lit(10))
+	}
+
+	// func (v Status) String() string
+	f.Func().Params(jen.Id("v").Id(param.Name)).Id("String").Params().String().Block( // {
+		// i := uint64(v) - 1
+		jen.Id("i").Op(":=").Uint64().Call(jen.Id("v")).Op(indexOffset(param.Start)),
+		// if i >= uint64(len(_StatusIndex)-1) {
+		jen.If(jen.Id("i").Op(">=").Uint64().Call(jen.Len(jen.Id("_"+param.Name+"Index")).Op("-").Lit(1))).Block(
+			// return "Status(" + strconv.FormatInt(int64(v), 10) + ")"
+			jen.Return(jen.Lit(param.Name+"(").Op("+").Add(format).Op("+").Lit(")")),
+		), // }
+		// return _StatusName[_StatusIndex[i]:_StatusIndex[i+1]]
+		jen.Return(jen.Id("_"+param.Name+"Name").Index(
+			jen.Id("_"+param.Name+"Index").Index(jen.Id("i")),
+			jen.Id("_"+param.Name+"Index").Index(jen.Id("i").Op("+").Lit(1)),
+		)),
+	) // }
+
+	f.Line()
+}
+
+// jenText returns expression converting v, a value of the enum, into its text representation.
+func jenText(param EnumParam, v string) *jen.Statement {
+	if param.IsInteger() {
+		return jen.Id(v).Dot("String").Call()
+	}
+	return jen.String().Call(jen.Id(v))
+}
+
+func jenInvalidError(f *jen.File, param EnumParam) {
+	errName := "Invalid" + param.Name + "Error"
+
+	// type InvalidEnumError struct {
+	//     Value string
+	// }
+	f.Comment(errName + " is returned when marshaling or unmarshaling a value that is not a variant of " + param.Name + ".")
+	f.Type().Id(errName).Struct(jen.Id("Value").String())
+
+	f.Line()
+
+	// func (e *InvalidEnumError) Error() string
+	f.Func().Params(jen.Id("e").Op("*").Id(errName)).Id("Error").Params().String().Block(
+		jen.Id("allowed").Op(":=").Make(jen.Index().String(), jen.Len(jen.Id("_"+param.Name+"All"))),
+		jen.For(jen.List(jen.Id("i"), jen.Id("v")).Op(":=").Range().Id("_"+param.Name+"All")).Block(
+			jen.Id("allowed").Index(jen.Id("i")).Op("=").Add(jenText(param, "v")),
+		),
+		jen.Return(jen.Qual("fmt", "Sprintf").Call(
+			jen.Lit("invalid "+param.Name+" %q: allowed variants are %q"),
+			jen.Id("e").Dot("Value"),
+			jen.Id("allowed"),
+		)),
+	)
+
+	f.Line()
+}
+
+func jenMarshal(f *jen.File, param EnumParam) {
+	errName := "Invalid" + param.Name + "Error"
+	text := func(v string) *jen.Statement {
+		return jenText(param, v)
+	}
+
+	marshaled := jen.Id("v")
+	if param.IsInteger() {
+		marshaled = text("v")
+	}
+	// func (v Enum) MarshalText() ([]byte, error)
+	f.Func().Params(jen.Id("v").Id(param.Name)).Id("MarshalText").Params().Params(jen.Index().Byte(), jen.Error()).Block(
+		jen.If(jen.Op("!").Id("Is"+param.Name).Call(jen.Id("v"))).Block(
+			jen.Return(jen.Nil(), jen.Op("&").Id(errName).Values(jen.Id("Value").Op(":").Add(text("v")))),
+		),
+		jen.Return(jen.Index().Byte().Call(marshaled), jen.Nil()),
+	)
+
+	f.Line()
+
+	// func (v *Enum) UnmarshalText(text []byte) error
+	f.Func().Params(jen.Id("v").Op("*").Id(param.Name)).Id("UnmarshalText").Params(jen.Id("text").Index().Byte()).Error().BlockFunc(func(g *jen.Group) {
+		if param.Flags {
+			g.List(jen.Id("parsed"), jen.Err()).Op(":=").Id("Parse" + param.Name).Call(jen.String().Call(jen.Id("text")))
+			g.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Op("&").Id(errName).Values(jen.Id("Value").Op(":").String().Call(jen.Id("text")))),
+			)
+			g.Op("*").Id("v").Op("=").Id("parsed")
+			g.Return(jen.Nil())
+			return
+		}
+		g.For(jen.List(jen.Id("_"), jen.Id("variant")).Op(":=").Range().Id("_" + param.Name + "All")).Block(
+			jen.If(text("variant").Op("==").String().Call(jen.Id("text"))).Block(
+				jen.Op("*").Id("v").Op("=").Id("variant"),
+				jen.Return(jen.Nil()),
+			),
+		)
+		g.Return(jen.Op("&").Id(errName).Values(jen.Id("Value").Op(":").String().Call(jen.Id("text"))))
+	})
+
+	f.Line()
+
+	// func (v Enum) MarshalJSON() ([]byte, error)
+	f.Func().Params(jen.Id("v").Id(param.Name)).Id("MarshalJSON").Params().Params(jen.Index().Byte(), jen.Error()).Block(
+		jen.List(jen.Id("text"), jen.Err()).Op(":=").Id("v").Dot("MarshalText").Call(),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Nil(), jen.Err()),
+		),
+		jen.Return(jen.Qual("encoding/json", "Marshal").Call(jen.String().Call(jen.Id("text")))),
+	)
+
+	f.Line()
+
+	// func (v *Enum) UnmarshalJSON(data []byte) error
+	f.Func().Params(jen.Id("v").Op("*").Id(param.Name)).Id("UnmarshalJSON").Params(jen.Id("data").Index().Byte()).Error().Block(
+		jen.Comment("As encoding/json does, null is a no-op."),
+		jen.If(jen.String().Call(jen.Id("data")).Op("==").Lit("null")).Block(
+			jen.Return(jen.Nil()),
+		),
+		jen.Var().Id("s").String(),
+		jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("data"), jen.Op("&").Id("s")),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Err()),
+		),
+		jen.Return(jen.Id("v").Dot("UnmarshalText").Call(jen.Index().Byte().Call(jen.Id("s")))),
+	)
+
+	f.Line()
+}
+
+func jenSQL(f *jen.File, param EnumParam) {
+	errName := "Invalid" + param.Name + "Error"
+	nullName := "Null" + param.Name
+
+	// func (v Enum) Value() (driver.Value, error)
+	stored := jen.String().Call(jen.Id("v"))
+	if param.IsInteger() {
+		stored = jen.Int64().Call(jen.Id("v"))
+	}
+	f.Func().Params(jen.Id("v").Id(param.Name)).Id("Value").Params().Params(jen.Qual("database/sql/driver", "Value"), jen.Error()).Block(
+		jen.If(jen.Op("!").Id("Is"+param.Name).Call(jen.Id("v"))).Block(
+			jen.Return(jen.Nil(), jen.Op("&").Id(errName).Values(jen.Id("Value").Op(":").Add(jenText(param, "v")))),
+		),
+		jen.Return(stored, jen.Nil()),
+	)
+
+	f.Line()
+
+	unsupported := jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("scanning "+param.Name+": unsupported type %T"), jen.Id("src")))
+
+	// func (v *Enum) Scan(src any) error
+	if param.IsInteger() {
+		f.Func().Params(jen.Id("v").Op("*").Id(param.Name)).Id("Scan").Params(jen.Id("src").Any()).Error().Block(
+			jen.Var().Defs(
+				jen.Id("n").Int64(),
+				jen.Err().Error(),
+			),
+			jen.Switch(jen.Id("x").Op(":=").Id("src").Assert(jen.Type())).Block(
+				jen.Case(jen.Int64()).Block(
+					jen.Id("n").Op("=").Id("x"),
+				),
+				jen.Case(jen.String()).Block(
+					jen.List(jen.Id("n"), jen.Err()).Op("=").Qual("strconv", "ParseInt").Call(jen.Id("x"), jen.Lit(10), jen.Lit(64)),
+				),
+				jen.Case(jen.Index().Byte()).Block(
+					jen.List(jen.Id("n"), jen.Err()).Op("=").Qual("strconv", "ParseInt").Call(jen.String().Call(jen.Id("x")), jen.Lit(10), jen.Lit(64)),
+				),
+				jen.Default().Block(unsupported),
+			),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("scanning "+param.Name+": %w"), jen.Err())),
+			),
+			jen.If(
+				jen.Int64().Call(jen.Id(param.Name).Call(jen.Id("n"))).Op("!=").Id("n").
+					Op("||").
+					Op("!").Id("Is"+param.Name).Call(jen.Id(param.Name).Call(jen.Id("n"))),
+			).Block(
+				jen.Return(jen.Op("&").Id(errName).Values(jen.Id("Value").Op(":").Qual("strconv", "FormatInt").Call(jen.Id("n"), jen.Lit(10)))),
+			),
+			jen.Op("*").Id("v").Op("=").Id(param.Name).Call(jen.Id("n")),
+			jen.Return(jen.Nil()),
+		)
+	} else {
+		f.Func().Params(jen.Id("v").Op("*").Id(param.Name)).Id("Scan").Params(jen.Id("src").Any()).Error().Block(
+			jen.Var().Id("s").String(),
+			jen.Switch(jen.Id("x").Op(":=").Id("src").Assert(jen.Type())).Block(
+				jen.Case(jen.String()).Block(
+					jen.Id("s").Op("=").Id("x"),
+				),
+				jen.Case(jen.Index().Byte()).Block(
+					jen.Id("s").Op("=").String().Call(jen.Id("x")),
+				),
+				jen.Default().Block(unsupported),
+			),
+			jen.If(jen.Op("!").Id("Is"+param.Name).Call(jen.Id(param.Name).Call(jen.Id("s")))).Block(
+				jen.Return(jen.Op("&").Id(errName).Values(jen.Id("Value").Op(":").Id("s"))),
+			),
+			jen.Op("*").Id("v").Op("=").Id(param.Name).Call(jen.Id("s")),
+			jen.Return(jen.Nil()),
+		)
+	}
+
+	f.Line()
+
+	// type NullEnum struct {
+	//     Enum  Enum
+	//     Valid bool
+	// }
+	f.Comment(nullName + " represents a " + param.Name + " that may be null.")
+	f.Type().Id(nullName).Struct(
+		jen.Id(param.Name).Id(param.Name),
+		jen.Id("Valid").Bool(),
+	)
+
+	f.Line()
+
+	// func (n *NullEnum) Scan(src any) error
+	f.Func().Params(jen.Id("n").Op("*").Id(nullName)).Id("Scan").Params(jen.Id("src").Any()).Error().Block(
+		jen.If(jen.Id("src").Op("==").Nil()).Block(
+			jen.Op("*").Id("n").Op("=").Id(nullName).Values(),
+			jen.Return(jen.Nil()),
+		),
+		jen.Err().Op(":=").Id("n").Dot(param.Name).Dot("Scan").Call(jen.Id("src")),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Err()),
+		),
+		jen.Id("n").Dot("Valid").Op("=").True(),
+		jen.Return(jen.Nil()),
+	)
+
+	f.Line()
+
+	// func (n NullEnum) Value() (driver.Value, error)
+	f.Func().Params(jen.Id("n").Id(nullName)).Id("Value").Params().Params(jen.Qual("database/sql/driver", "Value"), jen.Error()).Block(
+		jen.If(jen.Op("!").Id("n").Dot("Valid")).Block(
+			jen.Return(jen.Nil(), jen.Nil()),
+		),
+		jen.Return(jen.Id("n").Dot(param.Name).Dot("Value").Call()),
+	)
+
+	f.Line()
+}
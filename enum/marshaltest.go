@@ -0,0 +1,150 @@
+package enum
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"text/template"
+)
+
+var marshalTest = template.Must(template.New("marshal-test").Funcs(funcs).Parse(
+	`// Code generated by me. DO NOT EDIT.
+package {{.PackageName}}
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func Test{{.Name}}MarshalRoundTrip(t *testing.T) {
+	for _, v := range {{if .Flags}}append(_{{.Name}}All[:], _{{.Name}}Mask, 0){{else}}_{{.Name}}All{{end}} {
+		text, err := v.MarshalText()
+		if err != nil {
+			t.Fatalf("%v.MarshalText() failed: %v", v, err)
+		}
+		var fromText {{.Name}}
+		err = fromText.UnmarshalText(text)
+		if err != nil {
+			t.Fatalf("UnmarshalText(%q) failed: %v", text, err)
+		}
+		if fromText != v {
+			t.Errorf("text round trip: expected %v, but got %v", v, fromText)
+		}
+
+		bin, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("json.Marshal(%v) failed: %v", v, err)
+		}
+		var fromJSON {{.Name}}
+		err = json.Unmarshal(bin, &fromJSON)
+		if err != nil {
+			t.Fatalf("json.Unmarshal(%s) failed: %v", bin, err)
+		}
+		if fromJSON != v {
+			t.Errorf("json round trip: expected %v, but got %v", v, fromJSON)
+		}
+
+		err = json.Unmarshal([]byte("null"), &fromJSON)
+		if err != nil {
+			t.Fatalf("json.Unmarshal(null) failed: %v", err)
+		}
+		if fromJSON != v {
+			t.Errorf("json.Unmarshal(null) must leave %v unchanged, but got %v", v, fromJSON)
+		}
+	}
+}
+
+func Test{{.Name}}RejectsInvalid(t *testing.T) {
+	var (
+		v          {{.Name}}
+		invalidErr *Invalid{{.Name}}Error
+	)
+
+	err := v.UnmarshalText([]byte({{quote .InvalidText}}))
+	if !errors.As(err, &invalidErr) {
+		t.Errorf("UnmarshalText must return *Invalid{{.Name}}Error but got %v", err)
+	}
+
+	err = json.Unmarshal([]byte({{quote (quote .InvalidText)}}), &v)
+	if !errors.As(err, &invalidErr) {
+		t.Errorf("json.Unmarshal must return *Invalid{{.Name}}Error but got %v", err)
+	}
+{{if .InvalidValue}}
+	_, err = {{.Name}}({{.InvalidValue}}).MarshalText()
+	if !errors.As(err, &invalidErr) {
+		t.Errorf("MarshalText must return *Invalid{{.Name}}Error but got %v", err)
+	}
+
+	_, err = json.Marshal({{.Name}}({{.InvalidValue}}))
+	if !errors.As(err, &invalidErr) {
+		t.Errorf("json.Marshal must return *Invalid{{.Name}}Error but got %v", err)
+	}
+{{end -}}
+}
+`))
+
+type marshalTestParam struct {
+	EnumParam
+	// InvalidText is a text which is not a name of any variant.
+	InvalidText string
+	// InvalidValue is a Go expression of a value which is not a variant.
+	// Empty if every value of the underlying type is a variant.
+	InvalidValue string
+}
+
+// GenerateMarshalTest writes a test file for the enum described by param
+// which checks that every variant survives round trips through MarshalText/UnmarshalText and encoding/json,
+// that JSON null leaves it unchanged, and that values which are not a variant are rejected.
+//
+// param.Marshal must be true.
+func GenerateMarshalTest(w io.Writer, param EnumParam) error {
+	err := param.Validate()
+	if err != nil {
+		return err
+	}
+	if !param.Marshal {
+		return fmt.Errorf("enum %s: Marshal is not enabled", param.Name)
+	}
+
+	invalidText := "invalid"
+	for slices.Contains(param.Variants, invalidText) {
+		invalidText += "_"
+	}
+
+	var invalidValue string
+	switch {
+	case !param.IsInteger():
+		invalidValue = strconv.Quote(invalidText)
+	case param.Flags:
+		if len(param.Variants) < integerKinds[param.Underlying] {
+			invalidValue = "1 << " + strconv.Itoa(len(param.Variants))
+		}
+	default:
+		last := param.Start + int64(max(len(param.Variants)-1, 0))
+		lo, hi := integerRange(param.Underlying)
+		switch {
+		case len(param.Variants) == 0:
+			invalidValue = strconv.FormatInt(param.Start, 10)
+		case param.Start > lo:
+			invalidValue = strconv.FormatInt(param.Start-1, 10)
+		case hi < 0:
+			invalidValue = strconv.FormatUint(uint64(last)+1, 10)
+		case last < hi:
+			invalidValue = strconv.FormatInt(last+1, 10)
+		}
+	}
+
+	var buf bytes.Buffer
+	err = marshalTest.Execute(&buf, marshalTestParam{
+		EnumParam:    param,
+		InvalidText:  invalidText,
+		InvalidValue: invalidValue,
+	})
+	if err != nil {
+		return err
+	}
+	return writeFormatted(w, buf.Bytes())
+}
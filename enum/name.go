@@ -0,0 +1,278 @@
+package enum
+
+import (
+	"fmt"
+	"go/token"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Naming converts variants and except names into identifiers.
+// Convert returns the part of an identifier following a prefix, e.g. the type name.
+type Naming interface {
+	Name() string
+	Convert(s string) string
+}
+
+var namings = []Naming{
+	CapitalizeNaming{},
+	PascalNaming{},
+}
+
+// Namings returns all naming strategies implemented in this package.
+func Namings() []Naming {
+	return append([]Naming(nil), namings...)
+}
+
+// LookupNaming returns the naming strategy whose Name is name.
+// An empty name selects the default, CapitalizeNaming.
+func LookupNaming(name string) (Naming, bool) {
+	if name == "" {
+		return CapitalizeNaming{}, true
+	}
+	for _, n := range namings {
+		if n.Name() == name {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+// CapitalizeNaming capitalizes the first letter and replaces characters invalid in an identifier with '_',
+// e.g. "foo-bar" becomes "Foo_bar".
+type CapitalizeNaming struct{}
+
+func (CapitalizeNaming) Name() string {
+	return "capitalize"
+}
+
+func (CapitalizeNaming) Convert(s string) string {
+	return replaceInvalidChar(capitalize(s))
+}
+
+// PascalNaming splits s into words at characters invalid in an identifier, '_' and case changes,
+// then joins them capitalized, e.g. "foo_bar", "foo-bar", "foo bar" and "fooBar" all become "FooBar".
+// Words found in commonInitialisms are upper-cased entirely, e.g. "user_id" becomes "UserID".
+type PascalNaming struct{}
+
+func (PascalNaming) Name() string {
+	return "pascal"
+}
+
+func (PascalNaming) Convert(s string) string {
+	var b strings.Builder
+	for _, word := range splitWords(s) {
+		if upper := strings.ToUpper(word); commonInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		b.WriteString(capitalize(strings.ToLower(word)))
+	}
+	return b.String()
+}
+
+// commonInitialisms is the list of initialisms golint used to recommend to keep upper-cased.
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true,
+	"EOF": true, "GUID": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "LHS": true, "QPS": true, "RAM": true, "RHS": true,
+	"RPC": true, "SLA": true, "SMTP": true, "SQL": true, "SSH": true, "TCP": true,
+	"TLS": true, "TTL": true, "UDP": true, "UI": true, "UID": true, "UUID": true,
+	"URI": true, "URL": true, "UTF8": true, "VM": true, "XML": true, "XMPP": true,
+	"XSRF": true, "XSS": true,
+}
+
+// splitWords splits s at runs of characters other than letters and digits,
+// before an upper case letter following a lower case letter or a digit,
+// and before the last upper case letter of a run followed by a lower case letter, e.g. "HTTPServer" into "HTTP" and "Server".
+func splitWords(s string) []string {
+	var (
+		words []string
+		runes = []rune(s)
+		start = -1
+	)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		prev := runes[i-1]
+		if unicode.IsUpper(r) &&
+			(unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// naming returns the naming strategy selected by p.Naming.
+// p must be validated beforehand; an unknown Naming falls back to the default.
+func (p EnumParam) naming() Naming {
+	n, ok := LookupNaming(p.Naming)
+	if !ok {
+		return CapitalizeNaming{}
+	}
+	return n
+}
+
+// VariantIdent returns the identifier of the constant generated for variant.
+// It is Name followed by Idents[variant] if set, otherwise by variant converted by the naming strategy of p.
+func (p EnumParam) VariantIdent(variant string) string {
+	return p.Name + p.variantSuffix(variant)
+}
+
+func (p EnumParam) variantSuffix(variant string) string {
+	if ident, ok := p.Idents[variant]; ok {
+		return ident
+	}
+	return p.naming().Convert(variant)
+}
+
+// SubsetIdent returns the name of the subset type generated for the except named exceptName, e.g. EnumNotFoo.
+func (p EnumParam) SubsetIdent(exceptName string) string {
+	return p.Name + "Not" + p.naming().Convert(exceptName)
+}
+
+// SubsetVariantIdent returns the identifier of the constant of the subset type for the except named exceptName,
+// e.g. EnumNotFooBar.
+func (p EnumParam) SubsetVariantIdent(exceptName, variant string) string {
+	return p.SubsetIdent(exceptName) + p.variantSuffix(variant)
+}
+
+// ExceptIdent returns the name of the predicate function generated for the except named exceptName.
+func (p EnumParam) ExceptIdent(exceptName string) string {
+	return "Is" + p.Name + "Except" + p.naming().Convert(exceptName)
+}
+
+// IdentCollisionError is returned by EnumParam.Validate when several variants map to the same identifier.
+type IdentCollisionError struct {
+	Enum string
+	// Collisions maps each colliding identifier to the variants producing it, in the order of Variants.
+	Collisions map[string][]string
+	// Hint tells how to give the variants distinct identifiers where the param comes from, e.g. directives.
+	// If empty, setting Idents is suggested.
+	Hint string
+}
+
+func (e *IdentCollisionError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "enum %s: variants collide after conversion to identifiers", e.Enum)
+	idents := make([]string, 0, len(e.Collisions))
+	for ident := range e.Collisions {
+		idents = append(idents, ident)
+	}
+	slices.Sort(idents)
+	for _, ident := range idents {
+		fmt.Fprintf(&b, "\n\t%s: %q", ident, e.Collisions[ident])
+	}
+	hint := e.Hint
+	if hint == "" {
+		hint = "set Idents to give them distinct identifiers"
+	}
+	b.WriteString("\n" + hint)
+	return b.String()
+}
+
+func (p EnumParam) validateIdents() error {
+	overridden := make([]string, 0, len(p.Idents))
+	for variant := range p.Idents {
+		overridden = append(overridden, variant)
+	}
+	slices.Sort(overridden)
+	for _, variant := range overridden {
+		ident := p.Idents[variant]
+		if !slices.Contains(p.Variants, variant) {
+			return fmt.Errorf("enum %s: Idents has unknown variant %q", p.Name, variant)
+		}
+		if ident == "" || !token.IsIdentifier(p.Name+ident) {
+			return fmt.Errorf("enum %s: Idents[%q] = %q does not make a valid identifier", p.Name, variant, ident)
+		}
+	}
+
+	byIdent := make(map[string][]string, len(p.Variants))
+	for _, variant := range p.Variants {
+		ident := p.VariantIdent(variant)
+		if ident == p.Name {
+			return fmt.Errorf("enum %s: identifier of variant %q is same as the type name", p.Name, variant)
+		}
+		byIdent[ident] = append(byIdent[ident], variant)
+	}
+	maps.DeleteFunc(byIdent, func(_ string, variants []string) bool {
+		return len(variants) < 2
+	})
+	if len(byIdent) > 0 {
+		return &IdentCollisionError{Enum: p.Name, Collisions: byIdent}
+	}
+
+	// Identifiers generated for excepts must not clash with variants nor with each other.
+	declared := make(map[string]string)
+	for _, variant := range p.Variants {
+		declared[p.VariantIdent(variant)] = fmt.Sprintf("variant %q", variant)
+	}
+	declare := func(ident, what string) error {
+		if prev, ok := declared[ident]; ok {
+			return fmt.Errorf("enum %s: identifier %s of %s is also generated for %s", p.Name, ident, what, prev)
+		}
+		declared[ident] = what
+		return nil
+	}
+	for _, except := range p.Excepts {
+		err := declare(p.ExceptIdent(except.ExceptName), fmt.Sprintf("except %q", except.ExceptName))
+		if err != nil {
+			return err
+		}
+		if !except.Subset {
+			continue
+		}
+		err = declare(p.SubsetIdent(except.ExceptName), fmt.Sprintf("subset type of except %q", except.ExceptName))
+		if err != nil {
+			return err
+		}
+		for _, variant := range p.SubsetVariants(except) {
+			err := declare(
+				p.SubsetVariantIdent(except.ExceptName, variant),
+				fmt.Sprintf("variant %q of subset type of except %q", variant, except.ExceptName),
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// capitalize upper-cases the first rune of s.
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+func replaceInvalidChar(s string) string {
+	// As per Go programming specification.
+	// identifier = letter { letter | unicode_digit }.
+	// https://go.dev/ref/spec#Identifiers
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || r == '_' || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, s)
+}
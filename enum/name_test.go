@@ -0,0 +1,72 @@
+package enum
+
+import "testing"
+
+func TestNaming(t *testing.T) {
+	for _, tc := range []struct {
+		naming Naming
+		in     string
+		want   string
+	}{
+		{CapitalizeNaming{}, "foo", "Foo"},
+		{CapitalizeNaming{}, "foo_bar", "Foo_bar"},
+		{CapitalizeNaming{}, "b\"ar", "B_ar"},
+		{CapitalizeNaming{}, "élan", "Élan"},
+		{CapitalizeNaming{}, "日本", "日本"},
+		{CapitalizeNaming{}, "", ""},
+		{PascalNaming{}, "foo_bar", "FooBar"},
+		{PascalNaming{}, "foo-bar", "FooBar"},
+		{PascalNaming{}, "foo bar", "FooBar"},
+		{PascalNaming{}, "fooBar", "FooBar"},
+		{PascalNaming{}, "FOO_BAR", "FooBar"},
+		{PascalNaming{}, "user_id", "UserID"},
+		{PascalNaming{}, "api-url", "APIURL"},
+		{PascalNaming{}, "HTTPServer", "HTTPServer"},
+		{PascalNaming{}, "http_server", "HTTPServer"},
+		{PascalNaming{}, "élan vital", "ÉlanVital"},
+		{PascalNaming{}, "1st_place", "1stPlace"},
+		{PascalNaming{}, "--", ""},
+	} {
+		if got := tc.naming.Convert(tc.in); got != tc.want {
+			t.Errorf("%s: Convert(%q) = %q, want %q", tc.naming.Name(), tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestIdents(t *testing.T) {
+	p := EnumParam{
+		Name:     "Enum",
+		Variants: []string{"1st", "type", "b-ar"},
+		Idents:   map[string]string{"b-ar": "BDashAr"},
+		Excepts:  []EnumExceptParam{{ExceptName: "1st", ExcludedValiants: []string{"1st"}, Subset: true}},
+	}
+	for _, tc := range []struct {
+		got, want string
+	}{
+		{p.VariantIdent("1st"), "Enum1st"},
+		{p.VariantIdent("type"), "EnumType"},
+		{p.VariantIdent("b-ar"), "EnumBDashAr"},
+		{p.ExceptIdent("1st"), "IsEnumExcept1st"},
+		{p.SubsetIdent("1st"), "EnumNot1st"},
+		{p.SubsetVariantIdent("1st", "b-ar"), "EnumNot1stBDashAr"},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("got %q, want %q", tc.got, tc.want)
+		}
+	}
+}
+
+func TestLookupNaming(t *testing.T) {
+	for _, n := range Namings() {
+		got, ok := LookupNaming(n.Name())
+		if !ok || got != n {
+			t.Errorf("LookupNaming(%q) = %v, %t", n.Name(), got, ok)
+		}
+	}
+	if n, ok := LookupNaming(""); !ok || n != (CapitalizeNaming{}) {
+		t.Errorf(`LookupNaming("") = %v, %t, want the default`, n, ok)
+	}
+	if _, ok := LookupNaming("nonexistent"); ok {
+		t.Errorf("LookupNaming should report false for unknown naming")
+	}
+}
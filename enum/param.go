@@ -0,0 +1,218 @@
+package enum
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type EnumParam struct {
+	PackageName string            `json:"package"`
+	Name        string            `json:"name"`
+	Variants    []string          `json:"variants"`
+	Excepts     []EnumExceptParam `json:"excepts"`
+	// Idents overrides identifiers of variants' constants.
+	// Keys are variants and values are appended to Name, e.g. {"b-ar": "BDashAr"} names the constant EnumBDashAr.
+	// Use it to resolve collisions reported by Validate.
+	Idents map[string]string `json:"idents"`
+	// Docs documents variants. Keys are variants and values are rendered as comments above their constants,
+	// one comment line per line of the value.
+	Docs map[string]string `json:"docs"`
+	// Naming selects the strategy converting variants and except names into identifiers.
+	// It is one of names of Namings(), e.g. "capitalize" or "pascal".
+	// If empty, "capitalize" is assumed.
+	Naming string `json:"naming"`
+	// Underlying is the underlying type of the enum.
+	// It is either string or one of integer types, e.g. int, uint8.
+	// If empty, string is assumed.
+	Underlying string `json:"underlying"`
+	// Start is the value of the first variant of an integer enum.
+	// Variants following it are incremented by iota.
+	Start int64 `json:"start"`
+	// Marshal makes the enum implement encoding.TextMarshaler, encoding.TextUnmarshaler,
+	// json.Marshaler and json.Unmarshaler which reject values that are not a variant of the enum.
+	Marshal bool `json:"marshal"`
+	// SQL makes the enum implement sql.Scanner and driver.Valuer which reject values that are not a variant of the enum,
+	// and generates Null<Name> wrapper type to handle NULL.
+	// String enums are stored as text, integer enums as integer.
+	SQL bool `json:"sql"`
+	// Flags makes the enum a set of bit flags where each variant is 1 << iota.
+	// Underlying must be an unsigned integer type wide enough to hold all variants.
+	Flags bool `json:"flags"`
+}
+
+type EnumExceptParam struct {
+	Name             string   `json:"-"`
+	ExceptName       string   `json:"name"`
+	ExcludedValiants []string `json:"excluded"`
+	// Subset generates a distinct type holding only variants not excluded, e.g. type EnumNotFoo Enum,
+	// along with its constants, All array, checked conversion ToEnumNotFoo and widening method back to the enum.
+	Subset bool `json:"subset"`
+}
+
+func fillName(p EnumExceptParam, name string) EnumExceptParam {
+	p.Name = name
+	return p
+}
+
+// SubsetVariants returns variants of p which except does not exclude, in the order of Variants.
+func (p EnumParam) SubsetVariants(except EnumExceptParam) []string {
+	var variants []string
+	for _, v := range p.Variants {
+		if !slices.Contains(except.ExcludedValiants, v) {
+			variants = append(variants, v)
+		}
+	}
+	return variants
+}
+
+// integerKinds maps integer types allowed as Underlying to its width in bits.
+// int and uint are assumed to be 32 bits wide since it is the minimum size the Go spec guarantees.
+var integerKinds = map[string]int{
+	"int":    32,
+	"int8":   8,
+	"int16":  16,
+	"int32":  32,
+	"int64":  64,
+	"uint":   32,
+	"uint8":  8,
+	"uint16": 16,
+	"uint32": 32,
+	"uint64": 64,
+}
+
+// UnderlyingType returns the underlying type of the enum, defaulting to string.
+func (p EnumParam) UnderlyingType() string {
+	if p.Underlying == "" {
+		return "string"
+	}
+	return p.Underlying
+}
+
+// IsInteger reports whether the enum is an integer enum.
+func (p EnumParam) IsInteger() bool {
+	_, ok := integerKinds[p.Underlying]
+	return ok
+}
+
+// IsUnsigned reports whether the enum is an integer enum of unsigned type.
+func (p EnumParam) IsUnsigned() bool {
+	return p.IsInteger() && p.Underlying[0] == 'u'
+}
+
+// Validate reports an error if p can not be rendered into valid Go source code.
+func (p EnumParam) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("enum: empty Name")
+	}
+	if p.UnderlyingType() != "string" && !p.IsInteger() {
+		return fmt.Errorf("enum %s: unsupported underlying type %q", p.Name, p.Underlying)
+	}
+	if !p.IsInteger() && p.Start != 0 {
+		return fmt.Errorf("enum %s: Start is only allowed for integer enums", p.Name)
+	}
+	if _, ok := LookupNaming(p.Naming); !ok {
+		return fmt.Errorf("enum %s: unknown naming %q", p.Name, p.Naming)
+	}
+	for i, v := range p.Variants {
+		if v == "" {
+			return fmt.Errorf("enum %s: empty variant", p.Name)
+		}
+		if slices.Contains(p.Variants[:i], v) {
+			return fmt.Errorf("enum %s: duplicate variant %q", p.Name, v)
+		}
+	}
+	for _, except := range p.Excepts {
+		if except.ExceptName == "" {
+			return fmt.Errorf("enum %s: except with empty name", p.Name)
+		}
+		for _, v := range except.ExcludedValiants {
+			if !slices.Contains(p.Variants, v) {
+				return fmt.Errorf("enum %s: except %q excludes unknown variant %q", p.Name, except.ExceptName, v)
+			}
+		}
+		if except.Subset && len(p.SubsetVariants(except)) == 0 {
+			return fmt.Errorf("enum %s: subset type of except %q would have no variants", p.Name, except.ExceptName)
+		}
+	}
+	for v := range p.Docs {
+		if !slices.Contains(p.Variants, v) {
+			return fmt.Errorf("enum %s: doc for unknown variant %q", p.Name, v)
+		}
+	}
+	err := p.validateIdents()
+	if err != nil {
+		return err
+	}
+	if p.Flags {
+		return p.validateFlags()
+	}
+	if p.IsInteger() {
+		last := p.Start + int64(max(len(p.Variants)-1, 0))
+		if last < p.Start {
+			return fmt.Errorf("enum %s: value of last variant overflows int64", p.Name)
+		}
+		lo, hi := integerRange(p.Underlying)
+		if p.Start < lo || (hi >= 0 && last > hi) {
+			return fmt.Errorf(
+				"enum %s: values %d to %d do not fit in %s",
+				p.Name, p.Start, last, p.Underlying,
+			)
+		}
+	}
+	return nil
+}
+
+func (p EnumParam) validateFlags() error {
+	if !p.IsUnsigned() {
+		return fmt.Errorf("enum %s: flags must have an unsigned integer underlying type but is %q", p.Name, p.UnderlyingType())
+	}
+	if p.Start != 0 {
+		return fmt.Errorf("enum %s: Start is not allowed for flags", p.Name)
+	}
+	if len(p.Excepts) > 0 {
+		return fmt.Errorf("enum %s: Excepts are not supported for flags", p.Name)
+	}
+	if bits := integerKinds[p.Underlying]; len(p.Variants) > bits {
+		return fmt.Errorf(
+			"enum %s: %d variants do not fit in %s, which can hold at most %d flags",
+			p.Name, len(p.Variants), p.Underlying, bits,
+		)
+	}
+	for _, v := range p.Variants {
+		if strings.Contains(v, "|") {
+			return fmt.Errorf("enum %s: flag variant %q must not contain \"|\"", p.Name, v)
+		}
+	}
+	return nil
+}
+
+// integerRange returns the minimum and maximum value of integer type kind.
+// hi is -1 for uint64 which means no upper bound in int64 arithmetic.
+func integerRange(kind string) (lo, hi int64) {
+	bits := integerKinds[kind]
+	if kind[0] == 'u' {
+		if bits == 64 {
+			return 0, -1
+		}
+		return 0, 1<<bits - 1
+	}
+	return -1 << (bits - 1), 1<<(bits-1) - 1
+}
+
+// VariantDoc returns comment lines documenting variant, e.g. "// Foo is ...", or nil if Docs has no entry for it.
+func (p EnumParam) VariantDoc(variant string) []string {
+	doc, ok := p.Docs[variant]
+	if !ok {
+		return nil
+	}
+	lines := strings.Split(doc, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = "//"
+			continue
+		}
+		lines[i] = "// " + line
+	}
+	return lines
+}
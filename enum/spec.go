@@ -0,0 +1,408 @@
+package enum
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SpecError is an error found in an enum spec file.
+// Line and Column are 1-based; Column is 0 if unknown.
+type SpecError struct {
+	Filename string
+	Line     int
+	Column   int
+	Err      error
+}
+
+func (e *SpecError) Error() string {
+	if e.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d: %v", e.Filename, e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("%s:%d: %v", e.Filename, e.Line, e.Err)
+}
+
+func (e *SpecError) Unwrap() error {
+	return e.Err
+}
+
+// LoadSpecFile reads enum definitions from the file at path.
+// The format is chosen by the extension, .json or .csv.
+func LoadSpecFile(path string) ([]EnumParam, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		return ParseJSONSpec(path, f)
+	case ".csv":
+		return ParseCSVSpec(path, f)
+	default:
+		return nil, fmt.Errorf("%s: unknown spec format %q: must be .json or .csv", path, ext)
+	}
+}
+
+// ParseJSONSpec reads enum definitions in JSON from r.
+// The input is either a single object or an array of objects, e.g.
+//
+//	[
+//		{
+//			"package": "example",
+//			"name": "Status",
+//			"variants": ["active", "inactive", "deleted"],
+//			"underlying": "uint8",
+//			"start": 1,
+//			"marshal": true,
+//			"sql": true,
+//			"excepts": [{"name": "deleted", "excluded": ["deleted"]}]
+//		}
+//	]
+//
+// Unknown keys are rejected. filename is only used in error messages.
+func ParseJSONSpec(filename string, r io.Reader) ([]EnumParam, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	errAt := func(offset int64, err error) error {
+		line, col := offsetPosition(src, offset)
+		return &SpecError{Filename: filename, Line: line, Column: col, Err: err}
+	}
+
+	var (
+		objects []json.RawMessage
+		offsets []int64
+	)
+	dec := json.NewDecoder(bytes.NewReader(src))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, errAt(jsonErrorOffset(err, dec.InputOffset()), err)
+	}
+	switch tok {
+	case json.Delim('['):
+		for dec.More() {
+			offset := skipJSONSeparator(src, dec.InputOffset())
+			var raw json.RawMessage
+			err := dec.Decode(&raw)
+			if err != nil {
+				return nil, errAt(jsonErrorOffset(err, offset), err)
+			}
+			objects = append(objects, raw)
+			offsets = append(offsets, offset)
+		}
+		_, err = dec.Token()
+		if err != nil {
+			return nil, errAt(jsonErrorOffset(err, dec.InputOffset()), err)
+		}
+	case json.Delim('{'):
+		offset := skipJSONSeparator(src, 0)
+		dec = json.NewDecoder(bytes.NewReader(src))
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if err != nil {
+			return nil, errAt(jsonErrorOffset(err, offset), err)
+		}
+		objects = append(objects, raw)
+		offsets = append(offsets, offset)
+	default:
+		return nil, errAt(skipJSONSeparator(src, 0), errors.New("spec must be an object or an array of objects"))
+	}
+	if dec.More() {
+		return nil, errAt(skipJSONSeparator(src, dec.InputOffset()), errors.New("unexpected data after spec"))
+	}
+
+	params := make([]EnumParam, 0, len(objects))
+	for i, raw := range objects {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		var p EnumParam
+		err := dec.Decode(&p)
+		if err != nil {
+			// Errors without an offset, e.g. unknown fields, are reported at the start of the object.
+			return nil, errAt(offsets[i]+jsonErrorOffset(err, 0), err)
+		}
+		err = validateSpec(p, params)
+		if err != nil {
+			return nil, errAt(offsets[i], err)
+		}
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+func jsonErrorOffset(err error, fallback int64) int64 {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &syntaxErr):
+		return syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		return typeErr.Offset
+	}
+	return fallback
+}
+
+// skipJSONSeparator returns the offset of the first byte at or after offset
+// which is neither white space nor a comma.
+func skipJSONSeparator(src []byte, offset int64) int64 {
+	for offset < int64(len(src)) && strings.IndexByte(" \t\r\n,", src[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// offsetPosition converts a byte offset in src into a 1-based line and column.
+func offsetPosition(src []byte, offset int64) (line, col int) {
+	offset = min(offset, int64(len(src)))
+	before := src[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	col = len(before) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// csvColumns lists columns allowed in a CSV spec.
+var csvColumns = []string{"package", "name", "variant", "ident", "naming", "underlying", "start", "marshal", "sql", "flags", "excepts", "subsets"}
+
+// ParseCSVSpec reads enum definitions in CSV from r.
+// The first record is a header naming the columns, in any order:
+//
+//	package,name,variant,ident,naming,underlying,start,marshal,sql,flags,excepts,subsets
+//
+// name and variant are required, the others are optional.
+// Each following record adds a variant to the enum in its name column;
+// enums and variants keep the order of their first appearance.
+// Enum-wide columns (package, naming, underlying, start, marshal, sql, flags) may be left empty
+// but must not disagree between records of the same enum.
+// ident, if not empty, overrides the identifier of the variant as EnumParam.Idents does.
+// excepts lists the excepts, separated by "|", which exclude the variant of the record.
+// subsets lists the excepts, separated by "|", which generate subset types; it may be given in any record of the enum.
+// filename is only used in error messages.
+func ParseCSVSpec(filename string, r io.Reader) ([]EnumParam, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 0
+	cr.TrimLeadingSpace = true
+
+	readErr := func(err error) error {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return &SpecError{Filename: filename, Line: parseErr.Line, Column: parseErr.Column, Err: parseErr.Err}
+		}
+		return err
+	}
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, &SpecError{Filename: filename, Line: 1, Err: errors.New("missing header")}
+	}
+	if err != nil {
+		return nil, readErr(err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(h))
+		line, col := cr.FieldPos(i)
+		errAt := func(err error) error {
+			return &SpecError{Filename: filename, Line: line, Column: col, Err: err}
+		}
+		if !slices.Contains(csvColumns, h) {
+			return nil, errAt(fmt.Errorf("unknown column %q: allowed columns are %q", h, csvColumns))
+		}
+		if _, ok := columns[h]; ok {
+			return nil, errAt(fmt.Errorf("duplicate column %q", h))
+		}
+		columns[h] = i
+	}
+	for _, required := range []string{"name", "variant"} {
+		if _, ok := columns[required]; !ok {
+			return nil, &SpecError{Filename: filename, Line: 1, Err: fmt.Errorf("missing required column %q", required)}
+		}
+	}
+
+	var (
+		params []*EnumParam
+		byName = map[string]*EnumParam{}
+		// set records enum-wide columns already given for each enum, to detect disagreement.
+		set = map[string]map[string]string{}
+		// firstLine records the line an enum first appears for reporting validation errors.
+		firstLine = map[string]int{}
+		// subsets records excepts named in the subsets column and where they are, for each enum.
+		subsets = map[string][]csvSubset{}
+	)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, readErr(err)
+		}
+		recordLine, _ := cr.FieldPos(0)
+		field := func(column string) (string, int) {
+			i, ok := columns[column]
+			if !ok {
+				return "", 0
+			}
+			_, col := cr.FieldPos(i)
+			return strings.TrimSpace(record[i]), col
+		}
+		errAt := func(col int, err error) error {
+			return &SpecError{Filename: filename, Line: recordLine, Column: col, Err: err}
+		}
+
+		name, nameCol := field("name")
+		if name == "" {
+			return nil, errAt(nameCol, errors.New("empty name"))
+		}
+		p, ok := byName[name]
+		if !ok {
+			p = &EnumParam{Name: name}
+			byName[name] = p
+			set[name] = map[string]string{}
+			firstLine[name] = recordLine
+			params = append(params, p)
+		}
+
+		for _, column := range []string{"package", "naming", "underlying", "start", "marshal", "sql", "flags"} {
+			value, col := field(column)
+			if value == "" {
+				continue
+			}
+			if prev, ok := set[name][column]; ok {
+				if prev != value {
+					return nil, errAt(col, fmt.Errorf("enum %s: %s %q disagrees with %q given before", name, column, value, prev))
+				}
+				continue
+			}
+			set[name][column] = value
+			err := setCSVColumn(p, column, value)
+			if err != nil {
+				return nil, errAt(col, fmt.Errorf("enum %s: %s: %w", name, column, err))
+			}
+		}
+
+		variant, variantCol := field("variant")
+		if variant == "" {
+			return nil, errAt(variantCol, fmt.Errorf("enum %s: empty variant", name))
+		}
+		if slices.Contains(p.Variants, variant) {
+			return nil, errAt(variantCol, fmt.Errorf("enum %s: duplicate variant %q", name, variant))
+		}
+		p.Variants = append(p.Variants, variant)
+
+		if ident, _ := field("ident"); ident != "" {
+			if p.Idents == nil {
+				p.Idents = map[string]string{}
+			}
+			p.Idents[variant] = ident
+		}
+
+		if names, col := field("subsets"); names != "" {
+			for _, exceptName := range splitCSVList(names) {
+				subsets[name] = append(subsets[name], csvSubset{exceptName, recordLine, col})
+			}
+		}
+
+		excepts, _ := field("excepts")
+		for _, exceptName := range splitCSVList(excepts) {
+			i := 0
+			for i < len(p.Excepts) && p.Excepts[i].ExceptName != exceptName {
+				i++
+			}
+			if i == len(p.Excepts) {
+				p.Excepts = append(p.Excepts, EnumExceptParam{ExceptName: exceptName})
+			}
+			p.Excepts[i].ExcludedValiants = append(p.Excepts[i].ExcludedValiants, variant)
+		}
+	}
+
+	out := make([]EnumParam, 0, len(params))
+	for _, p := range params {
+		for _, subset := range subsets[p.Name] {
+			i := slices.IndexFunc(p.Excepts, func(e EnumExceptParam) bool { return e.ExceptName == subset.except })
+			if i < 0 {
+				return nil, &SpecError{
+					Filename: filename,
+					Line:     subset.line,
+					Column:   subset.col,
+					Err:      fmt.Errorf("enum %s: subsets names unknown except %q", p.Name, subset.except),
+				}
+			}
+			p.Excepts[i].Subset = true
+		}
+		err := validateSpec(*p, out)
+		if err != nil {
+			return nil, &SpecError{Filename: filename, Line: firstLine[p.Name], Err: err}
+		}
+		out = append(out, *p)
+	}
+	return out, nil
+}
+
+type csvSubset struct {
+	except    string
+	line, col int
+}
+
+// splitCSVList splits a "|" separated list in a CSV field, dropping empty elements.
+func splitCSVList(s string) []string {
+	var list []string
+	for _, e := range strings.Split(s, "|") {
+		e = strings.TrimSpace(e)
+		if e != "" {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+func setCSVColumn(p *EnumParam, column, value string) error {
+	var err error
+	switch column {
+	case "package":
+		p.PackageName = value
+	case "naming":
+		p.Naming = value
+	case "underlying":
+		p.Underlying = value
+	case "start":
+		p.Start, err = strconv.ParseInt(value, 10, 64)
+	case "marshal":
+		p.Marshal, err = strconv.ParseBool(value)
+	case "sql":
+		p.SQL, err = strconv.ParseBool(value)
+	case "flags":
+		p.Flags, err = strconv.ParseBool(value)
+	}
+	return err
+}
+
+// validateSpec checks p read from a spec file on top of EnumParam.Validate.
+// prev holds the enums read before p in the same file.
+func validateSpec(p EnumParam, prev []EnumParam) error {
+	if p.Name == "" {
+		return errors.New(`missing "name"`)
+	}
+	if len(p.Variants) == 0 {
+		return fmt.Errorf("enum %s: missing variants", p.Name)
+	}
+	for _, q := range prev {
+		if q.Name == p.Name && q.PackageName == p.PackageName {
+			return fmt.Errorf("enum %s: defined more than once", p.Name)
+		}
+	}
+	return p.Validate()
+}
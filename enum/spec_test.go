@@ -0,0 +1,182 @@
+package enum
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var specWant = []EnumParam{
+	{
+		PackageName: "example",
+		Name:        "Status",
+		Variants:    []string{"active", "inactive", "deleted"},
+		Excepts: []EnumExceptParam{
+			{
+				ExceptName:       "deleted",
+				ExcludedValiants: []string{"deleted"},
+				Subset:           true,
+			},
+		},
+		Underlying: "uint8",
+		Start:      1,
+		Marshal:    true,
+	},
+	{
+		PackageName: "example",
+		Name:        "Kind",
+		Variants:    []string{"a", "b\"c"},
+		Idents:      map[string]string{"b\"c": "BQuoteC"},
+	},
+}
+
+func TestParseJSONSpec(t *testing.T) {
+	src := `[
+	{
+		"package": "example",
+		"name": "Status",
+		"variants": ["active", "inactive", "deleted"],
+		"excepts": [{"name": "deleted", "excluded": ["deleted"], "subset": true}],
+		"underlying": "uint8",
+		"start": 1,
+		"marshal": true
+	},
+	{"package": "example", "name": "Kind", "variants": ["a", "b\"c"], "idents": {"b\"c": "BQuoteC"}}
+]`
+	params, err := ParseJSONSpec("enums.json", strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(params, specWant) {
+		t.Errorf("not equal:\nwant: %#v\ngot:  %#v", specWant, params)
+	}
+
+	single, err := ParseJSONSpec("enum.json", strings.NewReader(`{"name": "Kind", "variants": ["a"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(single) != 1 || single[0].Name != "Kind" {
+		t.Errorf("single object: got %#v", single)
+	}
+}
+
+func TestParseCSVSpec(t *testing.T) {
+	src := `package,name,variant,ident,underlying,start,marshal,excepts,subsets
+example,Status,active,,uint8,1,true,,
+example,Status,inactive,,,,,,
+example,Kind,a,,,,,,
+example,Status,deleted,,,,,deleted,deleted
+example,Kind,"b""c",BQuoteC,,,,,
+`
+	params, err := ParseCSVSpec("enums.csv", strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(params, specWant) {
+		t.Errorf("not equal:\nwant: %#v\ngot:  %#v", specWant, params)
+	}
+}
+
+func TestSpecErrorPosition(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		parse func(string, io.Reader) ([]EnumParam, error)
+		src   string
+		want  string
+	}{
+		{
+			"json syntax",
+			ParseJSONSpec,
+			"[\n\t{\"name\": \"A\", \"variants\": [\"a\"]},\n\t{\"name\": \"B\",, }\n]",
+			"spec:3:",
+		},
+		{
+			"json unknown field",
+			ParseJSONSpec,
+			"[\n\t{\"name\": \"A\", \"variants\": [\"a\"]},\n\t{\"name\": \"B\", \"variant\": [\"b\"]}\n]",
+			"spec:3:2: json: unknown field \"variant\"",
+		},
+		{
+			"json type",
+			ParseJSONSpec,
+			"{\n\t\"name\": \"A\",\n\t\"variants\": [\"a\"],\n\t\"start\": \"1\"\n}",
+			"spec:4:",
+		},
+		{
+			"json missing variants",
+			ParseJSONSpec,
+			"[\n\t{\"name\": \"A\", \"variants\": [\"a\"]},\n\n\t{\"name\": \"B\"}\n]",
+			"spec:4:2: enum B: missing variants",
+		},
+		{
+			"json invalid param",
+			ParseJSONSpec,
+			"[{\"name\": \"A\", \"variants\": [\"a\"], \"underlying\": \"float64\"}]",
+			"spec:1:2: enum A: unsupported underlying type",
+		},
+		{
+			"csv unknown column",
+			ParseCSVSpec,
+			"name,variant,kind\n",
+			"spec:1:14: unknown column \"kind\"",
+		},
+		{
+			"csv missing column",
+			ParseCSVSpec,
+			"name\nA\n",
+			"spec:1: missing required column \"variant\"",
+		},
+		{
+			"csv disagreement",
+			ParseCSVSpec,
+			"name,variant,underlying\nA,a,uint8\nA,b,int8\n",
+			"spec:3:5: enum A: underlying \"int8\" disagrees with \"uint8\"",
+		},
+		{
+			"csv bad bool",
+			ParseCSVSpec,
+			"name,variant,marshal\nA,a,yes\n",
+			"spec:2:5: enum A: marshal:",
+		},
+		{
+			"csv duplicate variant",
+			ParseCSVSpec,
+			"name,variant\nA,a\nA,a\n",
+			"spec:3:3: enum A: duplicate variant \"a\"",
+		},
+		{
+			"csv invalid param",
+			ParseCSVSpec,
+			"name,variant,underlying\nA,a,\nA,b,\nA,c,float64\n",
+			"spec:2: enum A: unsupported underlying type",
+		},
+		{
+			"csv unknown subset",
+			ParseCSVSpec,
+			"name,variant,excepts,subsets\nA,a,foo,\nA,b,,bar\n",
+			"spec:3:6: enum A: subsets names unknown except \"bar\"",
+		},
+		{
+			"csv quote",
+			ParseCSVSpec,
+			"name,variant\nA,\"a\n",
+			"spec:2:6: extraneous or missing \" in quoted-field",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.parse("spec", strings.NewReader(tc.src))
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			var specErr *SpecError
+			if !errors.As(err, &specErr) {
+				t.Fatalf("expected *SpecError, got %T: %v", err, err)
+			}
+			if !strings.HasPrefix(err.Error(), tc.want) {
+				t.Errorf("error should start with %q but is %q", tc.want, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,359 @@
+package enum
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"text/template"
+)
+
+var funcs = template.FuncMap{
+	"quote": func(s string) string {
+		return strconv.Quote(s)
+	},
+	"exceptParam": func(p EnumParam, e EnumExceptParam) exceptParam {
+		return exceptParam{EnumExceptParam: fillName(e, p.Name), Enum: p}
+	},
+	"nameTable":   makeNameTable,
+	"startOffset": startOffset,
+	"indexOffset": indexOffset,
+	"imports":     imports,
+}
+
+var (
+	pkg = template.Must(template.New("package").Funcs(funcs).Parse(
+		`// Code generated by me. DO NOT EDIT.
+package {{.PackageName}}
+
+{{with imports . -}}
+{{if eq (len .) 1}}import {{quote (index . 0)}}{{else}}import (
+{{range .}}	{{quote .}}
+{{end}}){{end}}
+{{- end}}
+
+type {{.Name}} {{.UnderlyingType}}
+
+{{if .Flags}}{{template "flags-const" .}}{{else if .IsInteger}}{{template "integer-const" .}}{{else}}{{template "string-const" .}}{{end}}
+
+var _{{.Name}}All = [...]{{.Name}}{{"{"}}{{range .Variants}}
+	{{$.VariantIdent .}},{{end}}
+}
+{{if .Flags}}
+// Is{{.Name}} reports whether v consists only of flags of {{.Name}}.
+func Is{{.Name}}(v {{.Name}}) bool {
+	return v&^_{{.Name}}Mask == 0
+}
+{{else}}
+func Is{{.Name}}(v {{.Name}}) bool {
+	return slices.Contains(_{{.Name}}All[:], v)
+}
+{{end}}{{if .Flags}}
+{{template "flags" .}}{{else if .IsInteger}}
+{{template "stringer" .}}{{end}}{{if or .Marshal .SQL}}
+{{template "invalid-error" .}}{{end}}{{if .Marshal}}
+{{template "marshal" .}}{{end}}{{if .SQL}}
+{{template "sql" .}}{{end}}
+{{range .Excepts}}
+{{template "except" (exceptParam $ .)}}{{if .Subset}}
+{{template "subset" (exceptParam $ .)}}{{end}}{{end}}`))
+	_ = template.Must(pkg.New("string-const").Parse(
+		`const (
+{{range .Variants}}{{range $.VariantDoc .}}	{{.}}
+{{end}}	{{$.VariantIdent .}} {{$.Name}} = {{quote .}}
+{{end -}}
+)`))
+	_ = template.Must(pkg.New("integer-const").Parse(
+		`const (
+{{range $i, $v := .Variants}}{{range $.VariantDoc $v}}	{{.}}
+{{end}}	{{$.VariantIdent $v}}{{if eq $i 0}} {{$.Name}} = iota{{startOffset $.Start}}{{end}}
+{{end -}}
+)`))
+	_ = template.Must(pkg.New("flags-const").Parse(
+		`const (
+{{range $i, $v := .Variants}}{{range $.VariantDoc $v}}	{{.}}
+{{end}}	{{$.VariantIdent $v}}{{if eq $i 0}} {{$.Name}} = 1 << iota{{end}}
+{{end -}}
+)
+
+const _{{.Name}}Mask {{.Name}} = {{range $i, $v := .Variants}}{{if $i}} | {{end}}{{$.VariantIdent $v}}{{else}}0{{end}}`))
+	_ = template.Must(pkg.New("name-table").Parse(
+		`{{$table := nameTable .Variants -}}
+const _{{.Name}}Name = {{quote $table.Names}}
+
+var _{{.Name}}Index = [...]{{$table.IndexType}}{{"{"}}{{range $i, $e := $table.Index}}{{if $i}}, {{end}}{{$e}}{{end}}}
+`))
+	_ = template.Must(pkg.New("flags").Parse(
+		`{{template "name-table" .}}
+// Has reports whether all flags set in flag are also set in v.
+func (v {{.Name}}) Has(flag {{.Name}}) bool {
+	return v&flag == flag
+}
+
+// Set returns v with flags in flag set.
+func (v {{.Name}}) Set(flag {{.Name}}) {{.Name}} {
+	return v | flag
+}
+
+// Clear returns v with flags in flag cleared.
+func (v {{.Name}}) Clear(flag {{.Name}}) {{.Name}} {
+	return v &^ flag
+}
+
+// Toggle returns v with flags in flag toggled.
+func (v {{.Name}}) Toggle(flag {{.Name}}) {{.Name}} {
+	return v ^ flag
+}
+
+// String returns names of flags set in v joined with "|".
+func (v {{.Name}}) String() string {
+	var names []string
+	for i, flag := range _{{.Name}}All {
+		if v&flag != 0 {
+			names = append(names, _{{.Name}}Name[_{{.Name}}Index[i]:_{{.Name}}Index[i+1]])
+		}
+	}
+	if rest := v &^ _{{.Name}}Mask; rest != 0 {
+		names = append(names, "{{.Name}}(0x"+strconv.FormatUint(uint64(rest), 16)+")")
+	}
+	return strings.Join(names, "|")
+}
+
+// Parse{{.Name}} parses names of flags joined with "|", e.g. "foo|bar", into {{.Name}}.
+func Parse{{.Name}}(s string) ({{.Name}}, error) {
+	var v {{.Name}}
+	if s == "" {
+		return v, nil
+	}
+NAMES:
+	for _, name := range strings.Split(s, "|") {
+		for i, flag := range _{{.Name}}All {
+			if _{{.Name}}Name[_{{.Name}}Index[i]:_{{.Name}}Index[i+1]] == name {
+				v |= flag
+				continue NAMES
+			}
+		}
+		return 0, fmt.Errorf("parsing {{.Name}}: unknown flag %q", name)
+	}
+	return v, nil
+}
+`))
+	_ = template.Must(pkg.New("stringer").Parse(
+		`{{template "name-table" .}}
+func (v {{.Name}}) String() string {
+	i := uint64(v){{indexOffset .Start}}
+	if i >= uint64(len(_{{.Name}}Index)-1) {
+		return "{{.Name}}(" + strconv.{{if .IsUnsigned}}FormatUint(uint64(v), 10){{else}}FormatInt(int64(v), 10){{end}} + ")"
+	}
+	return _{{.Name}}Name[_{{.Name}}Index[i]:_{{.Name}}Index[i+1]]
+}
+`))
+	_ = template.Must(pkg.New("invalid-error").Parse(
+		`// Invalid{{.Name}}Error is returned when marshaling or unmarshaling a value that is not a variant of {{.Name}}.
+type Invalid{{.Name}}Error struct {
+	Value string
+}
+
+func (e *Invalid{{.Name}}Error) Error() string {
+	allowed := make([]string, len(_{{.Name}}All))
+	for i, v := range _{{.Name}}All {
+		allowed[i] = {{if .IsInteger}}v.String(){{else}}string(v){{end}}
+	}
+	return fmt.Sprintf("invalid {{.Name}} %q: allowed variants are %q", e.Value, allowed)
+}
+`))
+	_ = template.Must(pkg.New("marshal").Parse(
+		`func (v {{.Name}}) MarshalText() ([]byte, error) {
+	if !Is{{.Name}}(v) {
+		return nil, &Invalid{{.Name}}Error{Value: {{if .IsInteger}}v.String(){{else}}string(v){{end}}}
+	}
+	return []byte({{if .IsInteger}}v.String(){{else}}v{{end}}), nil
+}
+
+func (v *{{.Name}}) UnmarshalText(text []byte) error {
+{{- if .Flags}}
+	parsed, err := Parse{{.Name}}(string(text))
+	if err != nil {
+		return &Invalid{{.Name}}Error{Value: string(text)}
+	}
+	*v = parsed
+	return nil
+}
+{{- else}}
+	for _, variant := range _{{.Name}}All {
+		if {{if .IsInteger}}variant.String(){{else}}string(variant){{end}} == string(text) {
+			*v = variant
+			return nil
+		}
+	}
+	return &Invalid{{.Name}}Error{Value: string(text)}
+}
+{{- end}}
+
+func (v {{.Name}}) MarshalJSON() ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+func (v *{{.Name}}) UnmarshalJSON(data []byte) error {
+	// As encoding/json does, null is a no-op.
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+	return v.UnmarshalText([]byte(s))
+}
+`))
+	_ = template.Must(pkg.New("sql").Parse(
+		`func (v {{.Name}}) Value() (driver.Value, error) {
+	if !Is{{.Name}}(v) {
+		return nil, &Invalid{{.Name}}Error{Value: {{if .IsInteger}}v.String(){{else}}string(v){{end}}}
+	}
+	return {{if .IsInteger}}int64(v){{else}}string(v){{end}}, nil
+}
+{{if .IsInteger}}
+func (v *{{.Name}}) Scan(src any) error {
+	var (
+		n   int64
+		err error
+	)
+	switch x := src.(type) {
+	case int64:
+		n = x
+	case string:
+		n, err = strconv.ParseInt(x, 10, 64)
+	case []byte:
+		n, err = strconv.ParseInt(string(x), 10, 64)
+	default:
+		return fmt.Errorf("scanning {{.Name}}: unsupported type %T", src)
+	}
+	if err != nil {
+		return fmt.Errorf("scanning {{.Name}}: %w", err)
+	}
+	if int64({{.Name}}(n)) != n || !Is{{.Name}}({{.Name}}(n)) {
+		return &Invalid{{.Name}}Error{Value: strconv.FormatInt(n, 10)}
+	}
+	*v = {{.Name}}(n)
+	return nil
+}
+{{else}}
+func (v *{{.Name}}) Scan(src any) error {
+	var s string
+	switch x := src.(type) {
+	case string:
+		s = x
+	case []byte:
+		s = string(x)
+	default:
+		return fmt.Errorf("scanning {{.Name}}: unsupported type %T", src)
+	}
+	if !Is{{.Name}}({{.Name}}(s)) {
+		return &Invalid{{.Name}}Error{Value: s}
+	}
+	*v = {{.Name}}(s)
+	return nil
+}
+{{end}}
+// Null{{.Name}} represents a {{.Name}} that may be null.
+type Null{{.Name}} struct {
+	{{.Name}} {{.Name}}
+	Valid     bool
+}
+
+func (n *Null{{.Name}}) Scan(src any) error {
+	if src == nil {
+		*n = Null{{.Name}}{}
+		return nil
+	}
+	err := n.{{.Name}}.Scan(src)
+	if err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+func (n Null{{.Name}}) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.{{.Name}}.Value()
+}
+`))
+	_ = template.Must(pkg.New("except").Parse(
+		`func {{.Enum.ExceptIdent .ExceptName}}(v {{.Name}}) bool {
+	return !slices.Contains(
+		[]{{.Name}}{{"{"}}{{range .ExcludedValiants}}
+			{{$.Enum.VariantIdent .}},{{end}}
+		},
+		v,
+	)
+}
+`))
+	_ = template.Must(pkg.New("subset").Parse(
+		`{{$t := .Enum.SubsetIdent .ExceptName -}}
+// {{$t}} is a subset of {{.Name}} without variants excluded by except {{quote .ExceptName}}.
+type {{$t}} {{.Name}}
+
+const (
+{{range .Enum.SubsetVariants .EnumExceptParam}}	{{$.Enum.SubsetVariantIdent $.ExceptName .}} = {{$t}}({{$.Enum.VariantIdent .}})
+{{end -}}
+)
+
+var _{{$t}}All = [...]{{$t}}{{"{"}}{{range .Enum.SubsetVariants .EnumExceptParam}}
+	{{$.Enum.SubsetVariantIdent $.ExceptName .}},{{end}}
+}
+
+// To{{$t}} converts v to {{$t}}.
+// It reports false if v is not a variant of {{$t}}.
+func To{{$t}}(v {{.Name}}) ({{$t}}, bool) {
+	if !slices.Contains(_{{$t}}All[:], {{$t}}(v)) {
+		var zero {{$t}}
+		return zero, false
+	}
+	return {{$t}}(v), true
+}
+
+// {{.Name}} widens v back to {{.Name}}.
+func (v {{$t}}) {{.Name}}() {{.Name}} {
+	return {{.Name}}(v)
+}
+{{if .Enum.IsInteger}}
+func (v {{$t}}) String() string {
+	return {{.Name}}(v).String()
+}
+{{end}}`))
+)
+
+// exceptParam is passed to the except template,
+// which needs the enum to render identifiers of excluded variants.
+type exceptParam struct {
+	EnumExceptParam
+	Enum EnumParam
+}
+
+// TemplateBackend generates enums by executing text/template.
+type TemplateBackend struct{}
+
+func (TemplateBackend) Name() string {
+	return "template"
+}
+
+func (TemplateBackend) Generate(w io.Writer, param EnumParam) error {
+	err := param.Validate()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	err = pkg.Execute(&buf, param)
+	if err != nil {
+		return err
+	}
+	return writeFormatted(w, buf.Bytes())
+}
@@ -0,0 +1,59 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "foo.go")
+
+	err := WriteFile(path, []byte("new"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFile(t, path, "new", 0o644)
+
+	err = os.Chmod(path, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = WriteFile(path, []byte("replaced"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFile(t, path, "replaced", 0o600)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("temporary files are left: %v", entries)
+	}
+
+	err = WriteFile(dir, []byte("dir"), 0o644)
+	if err == nil {
+		t.Errorf("writing over a directory must fail")
+	}
+}
+
+func assertFile(t *testing.T, path, content string, perm os.FileMode) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != perm {
+		t.Errorf("expected perm %v but got %v", perm, info.Mode().Perm())
+	}
+	bin, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bin) != content {
+		t.Errorf("expected content %q but got %q", content, bin)
+	}
+}
@@ -0,0 +1,258 @@
+// Package diff computes line-based unified diffs of generated files.
+package diff
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// context is the number of unchanged lines shown around changes.
+const context = 3
+
+type op struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// Unified returns the unified diff of old and new, labeled oldName and newName.
+// It returns nil if they are equal.
+//
+// Lines are matched by a shortest edit script, found in linear space and in time proportional to
+// the size of the files times the number of changed lines.
+func Unified(oldName, newName string, old, new []byte) []byte {
+	if bytes.Equal(old, new) {
+		return nil
+	}
+	ops := edits(splitLines(string(old)), splitLines(string(new)))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", oldName, newName)
+	for start := 0; start < len(ops); {
+		first := nextChange(ops, start)
+		if first < 0 {
+			break
+		}
+		// Extend the hunk while the next change is close enough for their contexts to overlap.
+		last := first
+		for {
+			next := nextChange(ops, last+1)
+			if next < 0 || next-last > 2*context {
+				break
+			}
+			last = next
+		}
+		lo, hi := max(first-context, 0), min(last+context+1, len(ops))
+		writeHunk(&buf, ops, lo, hi)
+		start = hi
+	}
+	return buf.Bytes()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// edits returns operations converting a into b.
+// Within a run of changes, deletions come before insertions.
+func edits(a, b []string) []op {
+	d := &differ{a: a, b: b, ops: make([]op, 0, len(a)+len(b))}
+	d.compare(0, len(a), 0, len(b))
+	ops := d.ops
+	for start := 0; start < len(ops); {
+		if ops[start].kind == ' ' {
+			start++
+			continue
+		}
+		end := start
+		for end < len(ops) && ops[end].kind != ' ' {
+			end++
+		}
+		slices.SortStableFunc(ops[start:end], func(x, y op) int {
+			return cmp.Compare(y.kind, x.kind) // '-' sorts before '+'
+		})
+		start = end
+	}
+	return ops
+}
+
+// differ computes a shortest edit script by the linear space variant of Myers' algorithm,
+// "An O(ND) Difference Algorithm and Its Variations", which bisects the edit graph at the middle of a shortest path.
+type differ struct {
+	a, b []string
+	ops  []op
+}
+
+// compare appends operations converting a[aLo:aHi] into b[bLo:bHi].
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.ops = append(d.ops, op{' ', d.a[aLo]})
+		aLo++
+		bLo++
+	}
+	var suffix int
+	for aLo < aHi-suffix && bLo < bHi-suffix && d.a[aHi-1-suffix] == d.b[bHi-1-suffix] {
+		suffix++
+	}
+	aHi, bHi = aHi-suffix, bHi-suffix
+
+	var (
+		x, y int
+		ok   bool
+	)
+	if aLo < aHi && bLo < bHi {
+		x, y, ok = d.bisect(aLo, aHi, bLo, bHi)
+	}
+	if ok {
+		d.compare(aLo, x, bLo, y)
+		d.compare(x, aHi, y, bHi)
+	} else {
+		// Either range is empty or they have nothing in common.
+		for _, l := range d.a[aLo:aHi] {
+			d.ops = append(d.ops, op{'-', l})
+		}
+		for _, l := range d.b[bLo:bHi] {
+			d.ops = append(d.ops, op{'+', l})
+		}
+	}
+
+	for _, l := range d.a[aHi : aHi+suffix] {
+		d.ops = append(d.ops, op{' ', l})
+	}
+}
+
+// bisect finds the point where paths searched from both ends of the edit graph of a[aLo:aHi] and b[bLo:bHi] meet,
+// which splits the graph into two halves each needing about half the edits.
+// Both ranges must be non-empty.
+// It reports false if no path meets, meaning the ranges have nothing in common.
+func (d *differ) bisect(aLo, aHi, bLo, bHi int) (x, y int, ok bool) {
+	n, m := aHi-aLo, bHi-bLo
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	// vf[offset+k] and vb[offset+k] are the furthest x reached on diagonal k from the start and from the end,
+	// the latter counted from the end, or -1 if not reached yet.
+	vf := make([]int, 2*maxD+2)
+	vb := make([]int, 2*maxD+2)
+	for i := range vf {
+		vf[i], vb[i] = -1, -1
+	}
+	vf[offset+1], vb[offset+1] = 0, 0
+	delta := n - m
+	// If delta is odd, paths meet while extending the forward path, and otherwise the backward one.
+	front := delta%2 != 0
+	// Diagonals trimmed since their paths left the edit graph.
+	var fStart, fEnd, bStart, bEnd int
+	for dd := range maxD {
+		for k := -dd + fStart; k <= dd-fEnd; k += 2 {
+			var x int
+			if k == -dd || k != dd && vf[offset+k-1] < vf[offset+k+1] {
+				x = vf[offset+k+1]
+			} else {
+				x = vf[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			vf[offset+k] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case front:
+				if kb := offset + delta - k; kb >= 0 && kb < len(vb) && vb[kb] != -1 && x >= n-vb[kb] {
+					return aLo + x, bLo + y, true
+				}
+			}
+		}
+		for k := -dd + bStart; k <= dd-bEnd; k += 2 {
+			var x int
+			if k == -dd || k != dd && vb[offset+k-1] < vb[offset+k+1] {
+				x = vb[offset+k+1]
+			} else {
+				x = vb[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.a[aHi-1-x] == d.b[bHi-1-y] {
+				x++
+				y++
+			}
+			vb[offset+k] = x
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !front:
+				if kf := offset + delta - k; kf >= 0 && kf < len(vf) && vf[kf] != -1 {
+					fx := vf[kf]
+					fy := offset + fx - kf
+					if fx >= n-x {
+						return aLo + fx, bLo + fy, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func nextChange(ops []op, from int) int {
+	for i := from; i < len(ops); i++ {
+		if ops[i].kind != ' ' {
+			return i
+		}
+	}
+	return -1
+}
+
+func writeHunk(buf *bytes.Buffer, ops []op, lo, hi int) {
+	var oldStart, newStart int
+	for _, o := range ops[:lo] {
+		if o.kind != '+' {
+			oldStart++
+		}
+		if o.kind != '-' {
+			newStart++
+		}
+	}
+	var oldCount, newCount int
+	for _, o := range ops[lo:hi] {
+		if o.kind != '+' {
+			oldCount++
+		}
+		if o.kind != '-' {
+			newCount++
+		}
+	}
+	fmt.Fprintf(buf, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, o := range ops[lo:hi] {
+		buf.WriteByte(o.kind)
+		buf.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			buf.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats a range of a hunk header. start is the number of lines preceding the hunk.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
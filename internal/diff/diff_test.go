@@ -0,0 +1,147 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		old, new string
+		expected string
+	}{
+		{
+			name: "equal",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+		},
+		{
+			name: "separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			new:  "1\n2x\n3\n4\n5\n6\n7\n8\n9\n10\n12\n",
+			expected: `--- old
++++ new
+@@ -1,5 +1,5 @@
+ 1
+-2
++2x
+ 3
+ 4
+ 5
+@@ -8,5 +8,4 @@
+ 8
+ 9
+ 10
+-11
+ 12
+`,
+		},
+		{
+			name: "merged hunk",
+			old:  "1\n2\n3\n4\n5\n",
+			new:  "0\n1\n3\n4\n5\n6\n",
+			expected: `--- old
++++ new
+@@ -1,5 +1,6 @@
++0
+ 1
+-2
+ 3
+ 4
+ 5
++6
+`,
+		},
+		{
+			name: "from empty",
+			old:  "",
+			new:  "a\nb",
+			expected: `--- old
++++ new
+@@ -0,0 +1,2 @@
++a
++b
+\ No newline at end of file
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := string(Unified("old", "new", []byte(tc.old), []byte(tc.new)))
+			if actual != tc.expected {
+				t.Errorf("not equal:\nexpected:\n%s\nactual:\n%s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b.
+func lcsLength(a, b []string) int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func TestEditsMinimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = fmt.Sprintf("%d\n", rng.Intn(5))
+		}
+		return lines
+	}
+	for range 1000 {
+		a, b := randomLines(), randomLines()
+		ops := edits(a, b)
+		var gotA, gotB []string
+		var changes int
+		for _, o := range ops {
+			if o.kind != '+' {
+				gotA = append(gotA, o.line)
+			}
+			if o.kind != '-' {
+				gotB = append(gotB, o.line)
+			}
+			if o.kind != ' ' {
+				changes++
+			}
+		}
+		if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+			t.Fatalf("edits of %q and %q do not convert one into the other: %v", a, b, ops)
+		}
+		if expected := len(a) + len(b) - 2*lcsLength(a, b); changes != expected {
+			t.Fatalf("edits of %q and %q have %d changes but the shortest has %d: %v", a, b, changes, expected, ops)
+		}
+	}
+}
+
+func TestUnifiedLargeFile(t *testing.T) {
+	var old, new strings.Builder
+	for i := range 100000 {
+		fmt.Fprintf(&old, "line %d\n", i)
+		if i == 10 {
+			new.WriteString("inserted\n")
+		}
+		if i != 99990 {
+			fmt.Fprintf(&new, "line %d\n", i)
+		}
+	}
+	// The region between the first and the last change spans the whole file,
+	// which must not need memory quadratic in its size.
+	d := string(Unified("old", "new", []byte(old.String()), []byte(new.String())))
+	if strings.Count(d, "@@ -") != 2 || !strings.Contains(d, "+inserted\n") || !strings.Contains(d, "-line 99990\n") {
+		t.Errorf("expected two hunks but got\n%s", d)
+	}
+}
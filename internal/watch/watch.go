@@ -0,0 +1,86 @@
+// Package watch detects changes of files by polling, without relying on OS specific notification.
+package watch
+
+import (
+	"context"
+	"os"
+	"slices"
+	"time"
+)
+
+type fileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+type snapshot map[string]fileState
+
+func take(paths []string) snapshot {
+	s := make(snapshot, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			s[path] = fileState{}
+			continue
+		}
+		s[path] = fileState{exists: true, modTime: info.ModTime(), size: info.Size()}
+	}
+	return s
+}
+
+// changed returns paths whose state differs between old and new.
+func changed(old, new snapshot) []string {
+	var paths []string
+	for path, state := range new {
+		if old[path] != state {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// Poll stats paths returned by files every interval and calls fn with paths changed, added or removed.
+// Changes are debounced: fn is called once a poll sees no further change, with all paths changed meanwhile, sorted.
+//
+// files is called again after each fn, so that inputs found by fn are watched,
+// and changes made by fn itself, e.g. rewritten sources, do not trigger another call.
+// Poll returns ctx.Err() when ctx is done.
+func Poll(ctx context.Context, interval time.Duration, files func() []string, fn func(changed []string)) error {
+	paths := files()
+	base := take(paths)
+	pending := make(map[string]bool)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		cur := take(paths)
+		if diff := changed(base, cur); len(diff) > 0 {
+			for _, path := range diff {
+				pending[path] = true
+			}
+			base = cur
+			continue
+		}
+		if len(pending) == 0 {
+			continue
+		}
+
+		var changedPaths []string
+		for path := range pending {
+			changedPaths = append(changedPaths, path)
+		}
+		slices.Sort(changedPaths)
+		clear(pending)
+		fn(changedPaths)
+
+		paths = files()
+		base = take(paths)
+	}
+}
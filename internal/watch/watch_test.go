@@ -0,0 +1,47 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPoll(t *testing.T) {
+	dir := t.TempDir()
+	foo, bar := filepath.Join(dir, "foo"), filepath.Join(dir, "bar")
+	err := os.WriteFile(foo, []byte("foo"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var calls [][]string
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		_ = os.WriteFile(foo, []byte("foo foo"), 0o644)
+		_ = os.WriteFile(bar, []byte("bar"), 0o644)
+	}()
+	err = Poll(
+		ctx,
+		10*time.Millisecond,
+		func() []string { return []string{foo, bar} },
+		func(changed []string) {
+			calls = append(calls, changed)
+			// Writes by fn itself must not be reported.
+			_ = os.WriteFile(foo, []byte("written by fn"), 0o644)
+			time.AfterFunc(50*time.Millisecond, cancel)
+		},
+	)
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled but got %v", err)
+	}
+	expected := [][]string{{bar, foo}}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected calls %v but got %v", expected, calls)
+	}
+}
@@ -0,0 +1,5 @@
+package example
+
+//go:generate go run github.com/ngicks/go-example-code-generation/cmd/goenum -backend jennifer -spec ../enums.csv -type Enum -output enum.go -test
+//go:generate go run github.com/ngicks/go-example-code-generation/cmd/goenum -backend jennifer -spec ../enums.csv -type Status -output status.go -test
+//go:generate go run github.com/ngicks/go-example-code-generation/cmd/goenum -backend jennifer -spec ../enums.csv -type Perm -output perm.go -test
@@ -0,0 +1,196 @@
+// Code generated by me. DO NOT EDIT.
+package example
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Perm uint8
+
+const (
+	PermRead Perm = 1 << iota
+	PermWrite
+	PermExec
+)
+
+const _PermMask Perm = PermRead | PermWrite | PermExec
+
+var _PermAll = [...]Perm{
+	PermRead,
+	PermWrite,
+	PermExec,
+}
+
+// IsPerm reports whether v consists only of flags of Perm.
+func IsPerm(v Perm) bool {
+	return v&^_PermMask == 0
+}
+
+const _PermName = "readwriteexec"
+
+var _PermIndex = [...]uint8{0, 4, 9, 13}
+
+// Has reports whether all flags set in flag are also set in v.
+func (v Perm) Has(flag Perm) bool {
+	return v&flag == flag
+}
+
+// Set returns v with flags in flag set.
+func (v Perm) Set(flag Perm) Perm {
+	return v | flag
+}
+
+// Clear returns v with flags in flag cleared.
+func (v Perm) Clear(flag Perm) Perm {
+	return v &^ flag
+}
+
+// Toggle returns v with flags in flag toggled.
+func (v Perm) Toggle(flag Perm) Perm {
+	return v ^ flag
+}
+
+// String returns names of flags set in v joined with "|".
+func (v Perm) String() string {
+	var names []string
+	for i, flag := range _PermAll {
+		if v&flag != 0 {
+			names = append(names, _PermName[_PermIndex[i]:_PermIndex[i+1]])
+		}
+	}
+	if rest := v &^ _PermMask; rest != 0 {
+		names = append(names, "Perm(0x"+strconv.FormatUint(uint64(rest), 16)+")")
+	}
+	return strings.Join(names, "|")
+}
+
+// ParsePerm parses names of flags joined with "|", e.g. "foo|bar", into Perm.
+func ParsePerm(s string) (Perm, error) {
+	var v Perm
+	if s == "" {
+		return v, nil
+	}
+NAMES:
+	for _, name := range strings.Split(s, "|") {
+		for i, flag := range _PermAll {
+			if _PermName[_PermIndex[i]:_PermIndex[i+1]] == name {
+				v |= flag
+				continue NAMES
+			}
+		}
+		return 0, fmt.Errorf("parsing Perm: unknown flag %q", name)
+	}
+	return v, nil
+}
+
+// InvalidPermError is returned when marshaling or unmarshaling a value that is not a variant of Perm.
+type InvalidPermError struct {
+	Value string
+}
+
+func (e *InvalidPermError) Error() string {
+	allowed := make([]string, len(_PermAll))
+	for i, v := range _PermAll {
+		allowed[i] = v.String()
+	}
+	return fmt.Sprintf("invalid Perm %q: allowed variants are %q", e.Value, allowed)
+}
+
+func (v Perm) MarshalText() ([]byte, error) {
+	if !IsPerm(v) {
+		return nil, &InvalidPermError{Value: v.String()}
+	}
+	return []byte(v.String()), nil
+}
+
+func (v *Perm) UnmarshalText(text []byte) error {
+	parsed, err := ParsePerm(string(text))
+	if err != nil {
+		return &InvalidPermError{Value: string(text)}
+	}
+	*v = parsed
+	return nil
+}
+
+func (v Perm) MarshalJSON() ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+func (v *Perm) UnmarshalJSON(data []byte) error {
+	// As encoding/json does, null is a no-op.
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+func (v Perm) Value() (driver.Value, error) {
+	if !IsPerm(v) {
+		return nil, &InvalidPermError{Value: v.String()}
+	}
+	return int64(v), nil
+}
+
+func (v *Perm) Scan(src any) error {
+	var (
+		n   int64
+		err error
+	)
+	switch x := src.(type) {
+	case int64:
+		n = x
+	case string:
+		n, err = strconv.ParseInt(x, 10, 64)
+	case []byte:
+		n, err = strconv.ParseInt(string(x), 10, 64)
+	default:
+		return fmt.Errorf("scanning Perm: unsupported type %T", src)
+	}
+	if err != nil {
+		return fmt.Errorf("scanning Perm: %w", err)
+	}
+	if int64(Perm(n)) != n || !IsPerm(Perm(n)) {
+		return &InvalidPermError{Value: strconv.FormatInt(n, 10)}
+	}
+	*v = Perm(n)
+	return nil
+}
+
+// NullPerm represents a Perm that may be null.
+type NullPerm struct {
+	Perm  Perm
+	Valid bool
+}
+
+func (n *NullPerm) Scan(src any) error {
+	if src == nil {
+		*n = NullPerm{}
+		return nil
+	}
+	err := n.Perm.Scan(src)
+	if err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+func (n NullPerm) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Perm.Value()
+}
@@ -2,15 +2,18 @@
 package example
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"slices"
 )
 
 type Enum string
 
 const (
-	EnumFoo Enum = "foo"
+	EnumFoo  Enum = "foo"
 	EnumB_ar Enum = "b\"ar"
-	EnumBaz Enum = "baz"
+	EnumBaz  Enum = "baz"
 )
 
 var _EnumAll = [...]Enum{
@@ -23,6 +26,106 @@ func IsEnum(v Enum) bool {
 	return slices.Contains(_EnumAll[:], v)
 }
 
+// InvalidEnumError is returned when marshaling or unmarshaling a value that is not a variant of Enum.
+type InvalidEnumError struct {
+	Value string
+}
+
+func (e *InvalidEnumError) Error() string {
+	allowed := make([]string, len(_EnumAll))
+	for i, v := range _EnumAll {
+		allowed[i] = string(v)
+	}
+	return fmt.Sprintf("invalid Enum %q: allowed variants are %q", e.Value, allowed)
+}
+
+func (v Enum) MarshalText() ([]byte, error) {
+	if !IsEnum(v) {
+		return nil, &InvalidEnumError{Value: string(v)}
+	}
+	return []byte(v), nil
+}
+
+func (v *Enum) UnmarshalText(text []byte) error {
+	for _, variant := range _EnumAll {
+		if string(variant) == string(text) {
+			*v = variant
+			return nil
+		}
+	}
+	return &InvalidEnumError{Value: string(text)}
+}
+
+func (v Enum) MarshalJSON() ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+func (v *Enum) UnmarshalJSON(data []byte) error {
+	// As encoding/json does, null is a no-op.
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+func (v Enum) Value() (driver.Value, error) {
+	if !IsEnum(v) {
+		return nil, &InvalidEnumError{Value: string(v)}
+	}
+	return string(v), nil
+}
+
+func (v *Enum) Scan(src any) error {
+	var s string
+	switch x := src.(type) {
+	case string:
+		s = x
+	case []byte:
+		s = string(x)
+	default:
+		return fmt.Errorf("scanning Enum: unsupported type %T", src)
+	}
+	if !IsEnum(Enum(s)) {
+		return &InvalidEnumError{Value: s}
+	}
+	*v = Enum(s)
+	return nil
+}
+
+// NullEnum represents a Enum that may be null.
+type NullEnum struct {
+	Enum  Enum
+	Valid bool
+}
+
+func (n *NullEnum) Scan(src any) error {
+	if src == nil {
+		*n = NullEnum{}
+		return nil
+	}
+	err := n.Enum.Scan(src)
+	if err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+func (n NullEnum) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Enum.Value()
+}
 
 func IsEnumExceptFoo(v Enum) bool {
 	return !slices.Contains(
@@ -42,3 +145,29 @@ func IsEnumExceptMuh(v Enum) bool {
 		v,
 	)
 }
+
+// EnumNotMuh is a subset of Enum without variants excluded by except "Muh".
+type EnumNotMuh Enum
+
+const (
+	EnumNotMuhBaz = EnumNotMuh(EnumBaz)
+)
+
+var _EnumNotMuhAll = [...]EnumNotMuh{
+	EnumNotMuhBaz,
+}
+
+// ToEnumNotMuh converts v to EnumNotMuh.
+// It reports false if v is not a variant of EnumNotMuh.
+func ToEnumNotMuh(v Enum) (EnumNotMuh, bool) {
+	if !slices.Contains(_EnumNotMuhAll[:], EnumNotMuh(v)) {
+		var zero EnumNotMuh
+		return zero, false
+	}
+	return EnumNotMuh(v), true
+}
+
+// Enum widens v back to Enum.
+func (v EnumNotMuh) Enum() Enum {
+	return Enum(v)
+}
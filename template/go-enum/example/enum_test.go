@@ -0,0 +1,73 @@
+// Code generated by me. DO NOT EDIT.
+package example
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestEnumMarshalRoundTrip(t *testing.T) {
+	for _, v := range _EnumAll {
+		text, err := v.MarshalText()
+		if err != nil {
+			t.Fatalf("%v.MarshalText() failed: %v", v, err)
+		}
+		var fromText Enum
+		err = fromText.UnmarshalText(text)
+		if err != nil {
+			t.Fatalf("UnmarshalText(%q) failed: %v", text, err)
+		}
+		if fromText != v {
+			t.Errorf("text round trip: expected %v, but got %v", v, fromText)
+		}
+
+		bin, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("json.Marshal(%v) failed: %v", v, err)
+		}
+		var fromJSON Enum
+		err = json.Unmarshal(bin, &fromJSON)
+		if err != nil {
+			t.Fatalf("json.Unmarshal(%s) failed: %v", bin, err)
+		}
+		if fromJSON != v {
+			t.Errorf("json round trip: expected %v, but got %v", v, fromJSON)
+		}
+
+		err = json.Unmarshal([]byte("null"), &fromJSON)
+		if err != nil {
+			t.Fatalf("json.Unmarshal(null) failed: %v", err)
+		}
+		if fromJSON != v {
+			t.Errorf("json.Unmarshal(null) must leave %v unchanged, but got %v", v, fromJSON)
+		}
+	}
+}
+
+func TestEnumRejectsInvalid(t *testing.T) {
+	var (
+		v          Enum
+		invalidErr *InvalidEnumError
+	)
+
+	err := v.UnmarshalText([]byte("invalid"))
+	if !errors.As(err, &invalidErr) {
+		t.Errorf("UnmarshalText must return *InvalidEnumError but got %v", err)
+	}
+
+	err = json.Unmarshal([]byte("\"invalid\""), &v)
+	if !errors.As(err, &invalidErr) {
+		t.Errorf("json.Unmarshal must return *InvalidEnumError but got %v", err)
+	}
+
+	_, err = Enum("invalid").MarshalText()
+	if !errors.As(err, &invalidErr) {
+		t.Errorf("MarshalText must return *InvalidEnumError but got %v", err)
+	}
+
+	_, err = json.Marshal(Enum("invalid"))
+	if !errors.As(err, &invalidErr) {
+		t.Errorf("json.Marshal must return *InvalidEnumError but got %v", err)
+	}
+}
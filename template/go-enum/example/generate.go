@@ -0,0 +1,5 @@
+package example
+
+//go:generate go run github.com/ngicks/go-example-code-generation/cmd/goenum -backend template -spec ../enums.json -type Enum -output enum.go -test
+//go:generate go run github.com/ngicks/go-example-code-generation/cmd/goenum -backend template -spec ../enums.json -type Status -output status.go -test
+//go:generate go run github.com/ngicks/go-example-code-generation/cmd/goenum -backend template -spec ../enums.json -type Perm -output perm.go -test
@@ -0,0 +1,73 @@
+// Code generated by me. DO NOT EDIT.
+package example
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestPermMarshalRoundTrip(t *testing.T) {
+	for _, v := range append(_PermAll[:], _PermMask, 0) {
+		text, err := v.MarshalText()
+		if err != nil {
+			t.Fatalf("%v.MarshalText() failed: %v", v, err)
+		}
+		var fromText Perm
+		err = fromText.UnmarshalText(text)
+		if err != nil {
+			t.Fatalf("UnmarshalText(%q) failed: %v", text, err)
+		}
+		if fromText != v {
+			t.Errorf("text round trip: expected %v, but got %v", v, fromText)
+		}
+
+		bin, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("json.Marshal(%v) failed: %v", v, err)
+		}
+		var fromJSON Perm
+		err = json.Unmarshal(bin, &fromJSON)
+		if err != nil {
+			t.Fatalf("json.Unmarshal(%s) failed: %v", bin, err)
+		}
+		if fromJSON != v {
+			t.Errorf("json round trip: expected %v, but got %v", v, fromJSON)
+		}
+
+		err = json.Unmarshal([]byte("null"), &fromJSON)
+		if err != nil {
+			t.Fatalf("json.Unmarshal(null) failed: %v", err)
+		}
+		if fromJSON != v {
+			t.Errorf("json.Unmarshal(null) must leave %v unchanged, but got %v", v, fromJSON)
+		}
+	}
+}
+
+func TestPermRejectsInvalid(t *testing.T) {
+	var (
+		v          Perm
+		invalidErr *InvalidPermError
+	)
+
+	err := v.UnmarshalText([]byte("invalid"))
+	if !errors.As(err, &invalidErr) {
+		t.Errorf("UnmarshalText must return *InvalidPermError but got %v", err)
+	}
+
+	err = json.Unmarshal([]byte("\"invalid\""), &v)
+	if !errors.As(err, &invalidErr) {
+		t.Errorf("json.Unmarshal must return *InvalidPermError but got %v", err)
+	}
+
+	_, err = Perm(1 << 3).MarshalText()
+	if !errors.As(err, &invalidErr) {
+		t.Errorf("MarshalText must return *InvalidPermError but got %v", err)
+	}
+
+	_, err = json.Marshal(Perm(1 << 3))
+	if !errors.As(err, &invalidErr) {
+		t.Errorf("json.Marshal must return *InvalidPermError but got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+// Code generated by me. DO NOT EDIT.
+package example
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+type Status uint8
+
+const (
+	StatusActive Status = iota + 1
+	StatusInactive
+	StatusDeleted
+)
+
+var _StatusAll = [...]Status{
+	StatusActive,
+	StatusInactive,
+	StatusDeleted,
+}
+
+func IsStatus(v Status) bool {
+	return slices.Contains(_StatusAll[:], v)
+}
+
+const _StatusName = "activeinactivedeleted"
+
+var _StatusIndex = [...]uint8{0, 6, 14, 21}
+
+func (v Status) String() string {
+	i := uint64(v) - 1
+	if i >= uint64(len(_StatusIndex)-1) {
+		return "Status(" + strconv.FormatUint(uint64(v), 10) + ")"
+	}
+	return _StatusName[_StatusIndex[i]:_StatusIndex[i+1]]
+}
+
+// InvalidStatusError is returned when marshaling or unmarshaling a value that is not a variant of Status.
+type InvalidStatusError struct {
+	Value string
+}
+
+func (e *InvalidStatusError) Error() string {
+	allowed := make([]string, len(_StatusAll))
+	for i, v := range _StatusAll {
+		allowed[i] = v.String()
+	}
+	return fmt.Sprintf("invalid Status %q: allowed variants are %q", e.Value, allowed)
+}
+
+func (v Status) MarshalText() ([]byte, error) {
+	if !IsStatus(v) {
+		return nil, &InvalidStatusError{Value: v.String()}
+	}
+	return []byte(v.String()), nil
+}
+
+func (v *Status) UnmarshalText(text []byte) error {
+	for _, variant := range _StatusAll {
+		if variant.String() == string(text) {
+			*v = variant
+			return nil
+		}
+	}
+	return &InvalidStatusError{Value: string(text)}
+}
+
+func (v Status) MarshalJSON() ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+func (v *Status) UnmarshalJSON(data []byte) error {
+	// As encoding/json does, null is a no-op.
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+func (v Status) Value() (driver.Value, error) {
+	if !IsStatus(v) {
+		return nil, &InvalidStatusError{Value: v.String()}
+	}
+	return int64(v), nil
+}
+
+func (v *Status) Scan(src any) error {
+	var (
+		n   int64
+		err error
+	)
+	switch x := src.(type) {
+	case int64:
+		n = x
+	case string:
+		n, err = strconv.ParseInt(x, 10, 64)
+	case []byte:
+		n, err = strconv.ParseInt(string(x), 10, 64)
+	default:
+		return fmt.Errorf("scanning Status: unsupported type %T", src)
+	}
+	if err != nil {
+		return fmt.Errorf("scanning Status: %w", err)
+	}
+	if int64(Status(n)) != n || !IsStatus(Status(n)) {
+		return &InvalidStatusError{Value: strconv.FormatInt(n, 10)}
+	}
+	*v = Status(n)
+	return nil
+}
+
+// NullStatus represents a Status that may be null.
+type NullStatus struct {
+	Status Status
+	Valid  bool
+}
+
+func (n *NullStatus) Scan(src any) error {
+	if src == nil {
+		*n = NullStatus{}
+		return nil
+	}
+	err := n.Status.Scan(src)
+	if err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+func (n NullStatus) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Status.Value()
+}